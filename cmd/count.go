@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/supreeth7/wcg/wc"
+)
+
+// parallelThreshold is the size from which a regular file is split into
+// ranges counted concurrently
+const parallelThreshold = 16 << 20
+
+const (
+	// openFilesHeadroom is the number of descriptors left below the limit on
+	// open files for the standard streams, the directories being walked and
+	// the runtime
+	openFilesHeadroom = 16
+
+	// defaultOpenFiles caps the number of files open at once where the
+	// limit is not known, and maxOpenFilesCap where it is very large
+	defaultOpenFiles = 256
+	maxOpenFilesCap  = 1 << 16
+)
+
+// maxOpenFiles returns how many files may be open at once, however many jobs
+// count them, so that long lists of files do not run out of descriptors
+func maxOpenFiles() int {
+	limit, ok := openFilesLimit()
+
+	switch {
+	case !ok:
+		return defaultOpenFiles
+	case limit <= openFilesHeadroom:
+		return 1
+	case limit-openFilesHeadroom > maxOpenFilesCap:
+		return maxOpenFilesCap
+	}
+
+	return int(limit - openFilesHeadroom)
+}
+
+// fileCounter counts files with the settings given on the command line
+type fileCounter struct {
+	opts wc.Options
+
+	// jobs is the number of files counted at once, and of goroutines
+	// counting a large file
+	jobs int
+
+	// mmap counts regular files from their mapping in memory
+	mmap bool
+
+	// openFiles holds a token for every file open
+	openFiles chan struct{}
+}
+
+// newFileCounter returns a fileCounter counting the counts selected by opts
+// with the given number of jobs
+func newFileCounter(opts wc.Options, jobs int) *fileCounter {
+	return &fileCounter{
+		opts:      opts,
+		jobs:      jobs,
+		openFiles: make(chan struct{}, maxOpenFiles()),
+	}
+}
+
+// countTask is a file to count and, once done is closed, its counts
+type countTask struct {
+	file   string
+	done   chan struct{}
+	counts wc.Counts
+	err    error
+}
+
+// countAll counts the given files on a pool of jobs goroutines and calls
+// report for each of them in order, as soon as the file and all the files
+// before it are counted. Only a bounded number of files are counted ahead of
+// the one reported next. The standard input is counted in order by the
+// caller's goroutine, so that "-" given twice reads it only once, and
+// invalidName is reported in order without being counted.
+func (fc *fileCounter) countAll(files []string, report func(file string, counts wc.Counts, err error)) {
+	var (
+		wg      sync.WaitGroup
+		tasks   = make(chan *countTask)
+		ordered = make(chan *countTask, 4*fc.jobs)
+	)
+
+	for i := 0; i < fc.jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for t := range tasks {
+				t.counts, t.err = fc.count(t.file)
+				close(t.done)
+			}
+		}()
+	}
+
+	go func() {
+		for _, file := range files {
+			t := &countTask{file: file, done: make(chan struct{})}
+			ordered <- t
+
+			if file != "-" && file != invalidName {
+				tasks <- t
+			}
+		}
+
+		close(tasks)
+		close(ordered)
+	}()
+
+	for t := range ordered {
+		switch t.file {
+		case "-":
+			t.counts, t.err = fc.count(t.file)
+		case invalidName:
+		default:
+			<-t.done
+		}
+
+		report(t.file, t.counts, t.err)
+	}
+
+	wg.Wait()
+}
+
+// count counts the given file, or the standard input when the file is "-"
+func (fc *fileCounter) count(file string) (wc.Counts, error) {
+	ctx := context.Background()
+
+	if file == "-" {
+		if fc.bytesOnly() {
+			if stdinInfo, err := os.Stdin.Stat(); err == nil && usableSize(stdinInfo) {
+				return fc.countSize(ctx, os.Stdin, stdinInfo.Size())
+			}
+		}
+
+		return wc.Count(ctx, os.Stdin, fc.opts)
+	}
+
+	fileInfo, err := checkIfFileExists(file)
+	if err != nil {
+		return wc.Counts{}, err
+	}
+
+	fc.openFiles <- struct{}{}
+	defer func() { <-fc.openFiles }()
+
+	input, err := os.Open(file)
+	if err != nil {
+		return wc.Counts{}, err
+	}
+	defer input.Close()
+
+	if fc.bytesOnly() && usableSize(fileInfo) {
+		return fc.countSize(ctx, input, fileInfo.Size())
+	}
+
+	if fc.mmap && usableSize(fileInfo) {
+		if counts, err := fc.countMapped(input, fileInfo.Size()); err == nil {
+			return counts, nil
+		}
+
+		// read the file instead, from its start
+		if _, err := input.Seek(0, io.SeekStart); err != nil {
+			return wc.Counts{}, err
+		}
+	}
+
+	if fc.jobs > 1 && fileInfo.Mode().IsRegular() && fileInfo.Size() >= parallelThreshold {
+		return wc.CountAt(ctx, input, fileInfo.Size(), fc.opts, fc.jobs)
+	}
+
+	return wc.Count(ctx, input, fc.opts)
+}
+
+// sizeCheckLength is the length of the end of a file that is read even when
+// its size is known, in case the file grew or its size is not to be trusted
+const sizeCheckLength = 16 << 10
+
+// bytesOnly reports whether bytes are the only count selected
+func (fc *fileCounter) bytesOnly() bool {
+	opts := fc.opts
+	return opts.Bytes && !opts.Lines && !opts.Words && !opts.Chars && !opts.MaxLineLength
+}
+
+// usableSize reports whether the size of a file can be trusted to count its
+// bytes. Pipes and devices have no size, and pseudo-files such as those in
+// /proc report a size of zero.
+func usableSize(info os.FileInfo) bool {
+	return info.Mode().IsRegular() && info.Size() > 0
+}
+
+// countSize counts the bytes of f, a regular file of the given size, from
+// its current offset without reading most of it. Like GNU wc, it seeks close
+// to the end and only reads what is left from there, so that a file larger
+// or smaller than its size is still counted right, and falls back to reading
+// the whole file when it cannot seek.
+func (fc *fileCounter) countSize(ctx context.Context, f *os.File, size int64) (wc.Counts, error) {
+	pos, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return wc.Count(ctx, f, fc.opts)
+	}
+
+	var skipped int64
+
+	if end := size - size%(sizeCheckLength+1); pos < end {
+		if _, err := f.Seek(end, io.SeekStart); err == nil {
+			skipped = end - pos
+		}
+	}
+
+	counts, err := wc.Count(ctx, f, fc.opts)
+	counts.Bytes += skipped
+
+	return counts, err
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/supreeth7/wcg/wc"
+)
+
+func TestCountAll(t *testing.T) {
+	dir := t.TempDir()
+
+	// the first files are the largest, so that later ones are done first
+	sizes := []int{1 << 22, 1 << 20, 10, 1 << 16, 0, 3}
+
+	var (
+		files []string
+		want  []wc.Counts
+	)
+
+	for i, size := range sizes {
+		path := filepath.Join(dir, string(rune('a'+i)))
+		if err := os.WriteFile(path, []byte(strings.Repeat("x\n", size/2)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		files = append(files, path)
+		want = append(want, wc.Counts{Lines: int64(size / 2), Bytes: int64(size / 2 * 2)})
+
+		if i == 2 || i == 4 {
+			files = append(files, "-")
+			want = append(want, wc.Counts{})
+		}
+	}
+
+	// the standard input is read by the first "-" only
+	want[3] = wc.Counts{Lines: 1, Bytes: 3}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if _, err := w.WriteString("ab\n"); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+
+	fc := newFileCounter(wc.Options{Lines: true, Bytes: true}, 4)
+
+	var (
+		got    []string
+		counts []wc.Counts
+	)
+
+	fc.countAll(files, func(file string, c wc.Counts, err error) {
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+		}
+
+		got = append(got, file)
+		counts = append(counts, c)
+	})
+
+	if !reflect.DeepEqual(got, files) {
+		t.Errorf("files reported in order: Actual:%q Expected:%q", got, files)
+	}
+
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("Actual:%+v Expected:%+v", counts, want)
+	}
+}
+
+func TestCountSize(t *testing.T) {
+	const size = 100<<10 + 123
+
+	tests := []struct {
+		name   string
+		offset int64
+		grown  int
+		want   int64
+	}{
+		{"from the start", 0, 0, size},
+		{"partly read", 1000, 0, size - 1000},
+		{"offset past the end", size + 10, 0, 0},
+		{"grown after stat", 0, 5000, size + 5000},
+	}
+
+	fc := newFileCounter(wc.Options{Bytes: true}, 1)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file")
+			if err := os.WriteFile(path, []byte(strings.Repeat("x", size)), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			f, err := os.OpenFile(path, os.O_RDWR, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			if test.grown > 0 {
+				if _, err := f.WriteAt([]byte(strings.Repeat("y", test.grown)), size); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if _, err := f.Seek(test.offset, io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+
+			counts, err := fc.countSize(context.Background(), f, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if counts.Bytes != test.want {
+				t.Errorf("Actual:%d Expected:%d", counts.Bytes, test.want)
+			}
+		})
+	}
+}
+
+func TestCountSizeOfStdin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", 50000)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// like the standard input of "(head -c 20000 >/dev/null; wcg -c) <file"
+	if _, err := f.Seek(20000, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+
+	stdin := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = stdin }()
+
+	counts, err := newFileCounter(wc.Options{Bytes: true}, 1).count("-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if counts.Bytes != 30000 {
+		t.Errorf("Actual:%d Expected:%d", counts.Bytes, 30000)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package cmd
+
+import (
+	"io/fs"
+	"os"
+)
+
+// sameDevice always reports true where the device of a file is not known,
+// so that --one-file-system walks every directory
+func sameDevice(a, b fs.FileInfo) bool {
+	return true
+}
+
+// sameFile reports whether a and b are the same file
+func sameFile(a, b fs.FileInfo) bool {
+	return os.SameFile(a, b)
+}
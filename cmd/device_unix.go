@@ -0,0 +1,41 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package cmd
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// sameDevice reports whether the files a and b are on the same file system
+func sameDevice(a, b fs.FileInfo) bool {
+	sa, ok := a.Sys().(*syscall.Stat_t)
+	if !ok {
+		return true
+	}
+
+	sb, ok := b.Sys().(*syscall.Stat_t)
+	if !ok {
+		return true
+	}
+
+	return sa.Dev == sb.Dev
+}
+
+// sameFile reports whether a and b are the same file, by their device and
+// inode numbers
+func sameFile(a, b fs.FileInfo) bool {
+	sa, ok := a.Sys().(*syscall.Stat_t)
+	if !ok {
+		return os.SameFile(a, b)
+	}
+
+	sb, ok := b.Sys().(*syscall.Stat_t)
+	if !ok {
+		return os.SameFile(a, b)
+	}
+
+	return sa.Dev == sb.Dev && sa.Ino == sb.Ino
+}
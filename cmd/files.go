@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// readFilesFrom returns the NUL-terminated file names listed in the given
+// file, or in the standard input when the file is "-". Entries that are not
+// valid file names are kept as invalidName, and why they are invalid is
+// returned in the same order.
+func readFilesFrom(file string) ([]string, []error, error) {
+	var (
+		data []byte
+		err  error
+	)
+
+	if file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot open %q for reading: %s", file, describeError(err))
+	}
+
+	names, invalid := parseFileList(data, file)
+
+	return names, invalid, nil
+}
+
+// invalidName stands for an entry of a list of file names that is not a valid
+// file name, so that it is reported at its place among the files counted. No
+// file has an empty name, so it cannot be mistaken for one.
+const invalidName = ""
+
+// errStdinName is the reason a name of "-" is invalid in a list of file names
+// read from the standard input
+var errStdinName = errors.New("when reading file names from standard input, no file name of '-' allowed")
+
+// parseFileList splits a NUL-terminated list of file names read from the
+// given source; the final name does not need a terminator. Invalid entries
+// are replaced with invalidName, and the reasons they are invalid returned in
+// order, with the same text as GNU wc, so that the other names are still
+// counted.
+func parseFileList(data []byte, source string) ([]string, []error) {
+	data = bytes.TrimSuffix(data, []byte{0})
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	fields := bytes.Split(data, []byte{0})
+	names := make([]string, 0, len(fields))
+
+	var invalid []error
+
+	for i, field := range fields {
+		name := string(field)
+
+		switch {
+		case name == "":
+			invalid = append(invalid, fmt.Errorf("%s:%d: invalid zero-length file name", source, i+1))
+		case name == "-" && source == "-":
+			invalid = append(invalid, errStdinName)
+			name = invalidName
+		}
+
+		names = append(names, name)
+	}
+
+	return names, invalid
+}
+
+// readPatterns returns the patterns listed one per line in the given file, or
+// in the standard input when the file is "-", skipping blank lines and lines
+// starting with "#"
+func readPatterns(file string) ([]string, error) {
+	var (
+		data []byte
+		err  error
+	)
+
+	if file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %q for reading: %s", file, describeError(err))
+	}
+
+	var patterns []string
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		patterns = append(patterns, line)
+	}
+
+	return patterns, nil
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFileList(t *testing.T) {
+	tests := []struct {
+		data    string
+		source  string
+		want    []string
+		invalid []string
+	}{
+		{"", "list", nil, nil},
+		{"t1\x00t2\x00", "list", []string{"t1", "t2"}, nil},
+		{"t1\x00t2", "list", []string{"t1", "t2"}, nil},
+		{"t1\x00\x00t2\x00", "l0", []string{"t1", invalidName, "t2"}, []string{"l0:2: invalid zero-length file name"}},
+		{"-\x00t1\x00", "list", []string{"-", "t1"}, nil},
+		{"t1\x00-\x00t2\x00", "-", []string{"t1", invalidName, "t2"}, []string{errStdinName.Error()}},
+	}
+
+	for _, test := range tests {
+		names, invalid := parseFileList([]byte(test.data), test.source)
+
+		var reasons []string
+		for _, err := range invalid {
+			reasons = append(reasons, err.Error())
+		}
+
+		if len(names) == 0 {
+			names = nil
+		}
+
+		if !reflect.DeepEqual(names, test.want) || !reflect.DeepEqual(reasons, test.invalid) {
+			t.Errorf("parseFileList(%q, %q): Actual:%q, %q Expected:%q, %q",
+				test.data, test.source, names, reasons, test.want, test.invalid)
+		}
+	}
+}
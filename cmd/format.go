@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/supreeth7/wcg/wc"
+)
+
+// formatter prints the counts of every file, in the order they are given,
+// and their total
+type formatter interface {
+	// file is called for every file once it is counted, with the error
+	// that kept it from being counted, if any
+	file(name string, c wc.Counts, err error)
+
+	// end is called once every file is counted, with their total and
+	// whether a total row is printed. It returns the error that kept the
+	// counts from being printed, if any.
+	end(total wc.Counts, showTotal bool) error
+}
+
+// totalMode selects when the total row is printed, as --total does
+type totalMode int
+
+const (
+	// totalAuto prints the total when more than one file is counted
+	totalAuto totalMode = iota
+
+	// totalAlways prints the total even for a single file
+	totalAlways
+
+	// totalOnly prints the total alone, without the rows of the files
+	totalOnly
+
+	// totalNever never prints the total
+	totalNever
+)
+
+// totalModes are the names of the total modes given to --total
+var totalModes = map[string]totalMode{
+	"auto":   totalAuto,
+	"always": totalAlways,
+	"only":   totalOnly,
+	"never":  totalNever,
+}
+
+// parseTotalMode returns the total mode of the given name
+func parseTotalMode(name string) (totalMode, error) {
+	mode, ok := totalModes[name]
+	if !ok {
+		return 0, fmt.Errorf("invalid argument %q for \"--total\"\nValid arguments are: auto, always, only, never", name)
+	}
+
+	return mode, nil
+}
+
+// showTotal reports whether the total row is printed after the rows of
+// count files. Directories walked print a total whatever the number of files
+// found under them.
+func (mode totalMode) showTotal(count int, walked bool) bool {
+	switch mode {
+	case totalAlways, totalOnly:
+		return true
+	case totalAuto:
+		return count > 1 || walked
+	}
+
+	return false
+}
+
+// outputOptions are the command-line options selecting how counts are
+// printed
+type outputOptions struct {
+	// output is the --output format
+	output string
+
+	// showNames prints the file names in the plain output
+	showNames bool
+
+	total totalMode
+
+	// numbers formats the counts of the plain output
+	numbers numberFormat
+}
+
+// newFormatter returns the formatter printing to w, as o selects, the counts
+// selected by opts of the given files and their total
+func newFormatter(o outputOptions, opts wc.Options, files []string, w io.Writer) (formatter, error) {
+	switch o.output {
+	case "", "plain":
+		f := &plainFormatter{
+			opts:       opts,
+			width:      o.numbers.width(numberWidth(files, opts)),
+			numbers:    o.numbers,
+			showNames:  o.showNames,
+			totalLabel: "total",
+			w:          w,
+		}
+
+		if o.total == totalOnly {
+			// like coreutils, the total alone is neither padded nor labelled
+			f.width = 1
+			f.totalLabel = ""
+		}
+
+		return f, nil
+	case "json":
+		return &jsonFormatter{opts: opts, noTotal: o.total == totalNever, w: w}, nil
+	case "ndjson":
+		return &ndjsonFormatter{opts: opts, noTotal: o.total == totalNever, enc: json.NewEncoder(w)}, nil
+	case "csv":
+		return newCSVFormatter(opts, ',', w), nil
+	case "tsv":
+		return newCSVFormatter(opts, '\t', w), nil
+	case "markdown":
+		return &markdownFormatter{opts: opts, w: w}, nil
+	}
+
+	return nil, fmt.Errorf("invalid output format %q\nValid formats are: plain, json, ndjson, csv, tsv, markdown", o.output)
+}
+
+// plainFormatter prints counts the way GNU wc does: right-aligned columns
+// of a shared width, followed by the file name
+type plainFormatter struct {
+	opts    wc.Options
+	width   int
+	numbers numberFormat
+
+	// showNames prints the file names, which are left out when only the
+	// standard input is counted
+	showNames bool
+
+	// totalLabel is the name printed on the total row
+	totalLabel string
+
+	w io.Writer
+}
+
+// file prints the counts selected for the given file, always in the order
+// newline, word, character, byte, maximum line length. Files that could not
+// be counted are only reported on the standard error.
+func (f *plainFormatter) file(name string, c wc.Counts, err error) {
+	if err != nil {
+		return
+	}
+
+	if !f.showNames {
+		name = ""
+	}
+
+	f.print(c, name)
+}
+
+// end prints the total row
+func (f *plainFormatter) end(total wc.Counts, showTotal bool) error {
+	if showTotal {
+		f.print(total, f.totalLabel)
+	}
+
+	return nil
+}
+
+// print prints the counts selected for a row
+func (f *plainFormatter) print(c wc.Counts, name string) {
+	cells := make([]string, 0, 5)
+	names := columnNames(f.opts)
+
+	for i, n := range values(f.opts, c) {
+		cells = append(cells, f.numbers.format(n, names[i] == "bytes"))
+	}
+
+	printResult(f.w, cells, f.width, name)
+}
+
+// values returns the counts of c selected by opts in the order they are
+// printed
+func values(opts wc.Options, c wc.Counts) []int64 {
+	var values []int64
+
+	if opts.Lines {
+		values = append(values, c.Lines)
+	}
+
+	if opts.Words {
+		values = append(values, c.Words)
+	}
+
+	if opts.Chars {
+		values = append(values, c.Chars)
+	}
+
+	if opts.Bytes {
+		values = append(values, c.Bytes)
+	}
+
+	if opts.MaxLineLength {
+		values = append(values, c.MaxLineLength)
+	}
+
+	return values
+}
+
+// columnNames returns the names of the counts selected by opts in the order
+// they are printed, the same as the fields of the json output
+func columnNames(opts wc.Options) []string {
+	var names []string
+
+	if opts.Lines {
+		names = append(names, "lines")
+	}
+
+	if opts.Words {
+		names = append(names, "words")
+	}
+
+	if opts.Chars {
+		names = append(names, "chars")
+	}
+
+	if opts.Bytes {
+		names = append(names, "bytes")
+	}
+
+	if opts.MaxLineLength {
+		names = append(names, "max_line_length")
+	}
+
+	return names
+}
+
+// printResult prints the given counts right-aligned to width characters,
+// separated by single spaces and followed by the file name when there is one
+func printResult(w io.Writer, cells []string, width int, file string) {
+	var row strings.Builder
+
+	for i, cell := range cells {
+		if i > 0 {
+			row.WriteByte(' ')
+		}
+
+		row.WriteString(strings.Repeat(" ", padding(width, cell)))
+		row.WriteString(cell)
+	}
+
+	if file != "" {
+		row.WriteByte(' ')
+		row.WriteString(file)
+	}
+
+	row.WriteByte('\n')
+
+	io.WriteString(w, row.String())
+}
+
+// numberWidth returns the width of the count columns, computed like
+// coreutils does from the total size of the inputs so that it is known
+// before any of them is read. Inputs that are not regular files, such as
+// pipes, widen the columns to at least 7 digits. A single count of a single
+// input is never padded.
+func numberWidth(files []string, opts wc.Options) int {
+	if len(files) == 1 && len(values(opts, wc.Counts{})) == 1 {
+		return 1
+	}
+
+	var (
+		minWidth = 1
+		total    int64
+	)
+
+	for _, file := range files {
+		var (
+			info os.FileInfo
+			err  error
+		)
+
+		if file == "-" {
+			info, err = os.Stdin.Stat()
+		} else {
+			info, err = os.Stat(file)
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if info.Mode().IsRegular() {
+			total += info.Size()
+		} else {
+			minWidth = 7
+		}
+	}
+
+	width := len(strconv.FormatInt(total, 10))
+	if width < minWidth {
+		width = minWidth
+	}
+
+	return width
+}
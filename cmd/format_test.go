@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/supreeth7/wcg/wc"
+)
+
+func TestTotalMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{"auto", " 1  2 10 a.txt\n"},
+		{"always", " 1  2 10 a.txt\n 1  2 10 total\n"},
+		{"only", "1 2 10\n"},
+		{"never", " 1  2 10 a.txt\n"},
+	}
+
+	for _, test := range tests {
+		mode, err := parseTotalMode(test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+
+		f, err := newFormatter(outputOptions{output: "plain", showNames: true, total: mode}, defaultCounts, nil, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the width of the columns is computed from the files counted
+		if p := f.(*plainFormatter); mode != totalOnly {
+			p.width = 2
+		}
+
+		counts := wc.Counts{Lines: 1, Words: 2, Bytes: 10}
+		if mode != totalOnly {
+			f.file("a.txt", counts, nil)
+		}
+
+		f.end(counts, mode.showTotal(1, false))
+
+		if got := buf.String(); got != test.output {
+			t.Errorf("--total=%s: Actual:%q Expected:%q", test.name, got, test.output)
+		}
+	}
+
+	if _, err := parseTotalMode("sometimes"); err == nil {
+		t.Error("an invalid total mode did not fail")
+	}
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"regexp"
+	"unicode/utf8"
+)
+
+// sniffLength is the length of the start of a file looked at to tell
+// whether it is generated or binary
+const sniffLength = 4 << 10
+
+// generatedHeader matches the comment marking generated Go files, as
+// described at https://golang.org/s/generatedcode
+var generatedHeader = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// vendoredDirs are the names of the directories holding third-party code
+var vendoredDirs = map[string]bool{
+	"vendor":       true,
+	"node_modules": true,
+}
+
+// skipReason returns why the file at path should not be counted as
+// hand-written text, or "" when it should be counted. Files that cannot be
+// read are counted, so that the error is reported.
+func skipReason(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return ""
+	}
+
+	head = head[:n]
+
+	switch {
+	case bytes.IndexByte(head, 0) >= 0:
+		return "binary file, it has NUL bytes"
+	case !looksLikeText(head, n == sniffLength):
+		return "binary file, it does not look like text"
+	case hasGeneratedHeader(head):
+		return "generated file"
+	}
+
+	return ""
+}
+
+// hasGeneratedHeader reports whether the comments and blank lines at the
+// start of head, before any code, include the comment marking generated
+// files. The comment may follow other comments, such as a license, but not
+// the package clause.
+func hasGeneratedHeader(head []byte) bool {
+	inBlock := false
+
+	for _, line := range bytes.Split(head, []byte("\n")) {
+		line = bytes.TrimSuffix(line, []byte("\r"))
+
+		if inBlock {
+			end := bytes.Index(line, []byte("*/"))
+			if end < 0 {
+				continue
+			}
+
+			inBlock = false
+			line = line[end+2:]
+		}
+
+		rest := bytes.TrimSpace(line)
+
+		switch {
+		case len(rest) == 0:
+		case bytes.HasPrefix(rest, []byte("//")):
+			if generatedHeader.Match(line) {
+				return true
+			}
+		case bytes.HasPrefix(rest, []byte("/*")):
+			end := bytes.Index(rest[2:], []byte("*/"))
+			if end < 0 {
+				inBlock = true
+			} else if len(bytes.TrimSpace(rest[2+end+2:])) > 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return false
+}
+
+// looksLikeText reports whether b looks like text, the way Perl's -T does:
+// at most 30% of it are control characters other than white space, backspace
+// and escape, or bytes that are not ASCII when b is not valid UTF-8. When b is
+// only the start of a file, a character it cuts short is left out.
+func looksLikeText(b []byte, truncated bool) bool {
+	if len(b) == 0 {
+		return true
+	}
+
+	if truncated {
+		for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(b[i]) {
+				if !utf8.FullRune(b[i:]) {
+					b = b[:i]
+				}
+
+				break
+			}
+		}
+	}
+
+	valid := utf8.Valid(b)
+	odd := 0
+
+	for _, c := range b {
+		switch {
+		case c == '\t', c == '\n', c == '\v', c == '\f', c == '\r', c == '\b', c == 0x1b:
+		case c < 0x20, c == 0x7f:
+			odd++
+		case c >= utf8.RuneSelf && !valid:
+			odd++
+		}
+	}
+
+	return odd*10 <= len(b)*3
+}
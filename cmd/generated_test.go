@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSkipReason(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"main.go", "package main\n\nfunc main() {}\n", ""},
+		{"zz_generated.go", "// Code generated by stringer. DO NOT EDIT.\n\npackage main\n", "generated file"},
+		{"crlf.go", "// Code generated by protoc. DO NOT EDIT.\r\npackage pb\r\n", "generated file"},
+		{"license.go", "/*\n * Copyright 2024 The Authors.\n */\n\n// Code generated by mockgen. DO NOT EDIT.\n\npackage mock\n", "generated file"},
+		{"late.go", "package main\n\n// Code generated by hand. DO NOT EDIT.\n", ""},
+		{"mention.go", "package main\n\n// A \"Code generated ... DO NOT EDIT.\" header marks generated files.\n", ""},
+		{"utf8.txt", strings.Repeat("日本語のテキスト\n", 1000), ""},
+		{"latin1.txt", "caf\xe9 cr\xe8me br\xfbl\xe9e\n", ""},
+		{"image.png", "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR", "binary file, it has NUL bytes"},
+		{"noise.bin", strings.Repeat("\x01\x02\x03\xff\xfe", 100), "binary file, it does not look like text"},
+		{"empty.txt", "", ""},
+	}
+
+	for _, test := range tests {
+		path := filepath.Join(dir, test.name)
+		if err := os.WriteFile(path, []byte(test.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := skipReason(path); got != test.want {
+			t.Errorf("skipReason(%s): Actual:%q Expected:%q", test.name, got, test.want)
+		}
+	}
+}
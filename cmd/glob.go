@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"errors"
+	"path"
+	"strings"
+)
+
+// errEmptyPattern is returned for a pattern that would match every file
+var errEmptyPattern = errors.New("empty pattern")
+
+// globPattern is a compiled pattern matching file paths with the syntax of
+// .gitignore files. It matches slash-separated paths relative to the
+// directory it applies to: "*", "?" and "[...]" match within a single path
+// element, and "**" matches any number of them. A pattern with no slash, or
+// only a trailing one, matches the name of a file at any depth, while any
+// other pattern is anchored to the directory it applies to.
+type globPattern struct {
+	// elements are the patterns of the path elements, "**" included
+	elements []string
+
+	// dirOnly only matches directories, for patterns ending with a slash
+	dirOnly bool
+
+	// negate re-includes what earlier patterns ignore, for the lines of
+	// ignore files starting with "!"
+	negate bool
+}
+
+// compileGlob compiles the given pattern, failing when it is empty or one of
+// its elements is malformed
+func compileGlob(pattern string) (globPattern, error) {
+	var g globPattern
+
+	if strings.HasSuffix(pattern, "/") {
+		g.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	if pattern == "" {
+		return globPattern{}, errEmptyPattern
+	}
+
+	if !anchored {
+		g.elements = append(g.elements, "**")
+	}
+
+	for _, element := range strings.Split(pattern, "/") {
+		if element == "" {
+			continue
+		}
+
+		if _, err := path.Match(element, ""); err != nil {
+			return globPattern{}, err
+		}
+
+		g.elements = append(g.elements, element)
+	}
+
+	return g, nil
+}
+
+// match reports whether the pattern matches the slash-separated path name,
+// which is a directory when isDir is set
+func (g globPattern) match(name string, isDir bool) bool {
+	if g.dirOnly && !isDir {
+		return false
+	}
+
+	return matchElements(g.elements, strings.Split(name, "/"))
+}
+
+// matchElements reports whether the element patterns match the elements of a
+// path. A "**" matches any number of elements, but at least one when it ends
+// the pattern, so that "dir/**" matches what is inside dir and not dir itself.
+func matchElements(patterns, names []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			rest := patterns[1:]
+
+			skip := 0
+			if len(rest) == 0 {
+				skip = 1
+			}
+
+			for ; skip <= len(names); skip++ {
+				if matchElements(rest, names[skip:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(names) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(patterns[0], names[0]); !ok {
+			return false
+		}
+
+		patterns, names = patterns[1:], names[1:]
+	}
+
+	return len(names) == 0
+}
+
+// matchAny reports whether any of the patterns matches the given path
+func matchAny(patterns []globPattern, name string, isDir bool) bool {
+	for _, g := range patterns {
+		if g.match(name, isDir) {
+			return true
+		}
+	}
+
+	return false
+}
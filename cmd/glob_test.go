@@ -0,0 +1,83 @@
+package cmd
+
+import "testing"
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		isDir   bool
+		want    bool
+	}{
+		{"*.go", "main.go", false, true},
+		{"*.go", "cmd/root.go", false, true},
+		{"*.go", "main.go.txt", false, false},
+		{"/*.go", "cmd/root.go", false, false},
+		{"cmd/*.go", "cmd/root.go", false, true},
+		{"cmd/*.go", "x/cmd/root.go", false, false},
+		{"vendor/**", "vendor/a/b/c.go", false, true},
+		{"vendor/**", "vendor", true, false},
+		{"**/testdata/*", "a/b/testdata/x", false, true},
+		{"**/testdata/*", "testdata/x", false, true},
+		{"a/**/b", "a/b", false, true},
+		{"a/**/b", "a/x/y/b", false, true},
+		{"build/", "build", true, true},
+		{"build/", "build", false, false},
+		{"[ab]?.txt", "docs/b1.txt", false, true},
+		{`\*.txt`, "*.txt", false, true},
+		{`\*.txt`, "a.txt", false, false},
+	}
+
+	for _, test := range tests {
+		g, err := compileGlob(test.pattern)
+		if err != nil {
+			t.Fatalf("compileGlob(%q): %v", test.pattern, err)
+		}
+
+		if got := g.match(test.name, test.isDir); got != test.want {
+			t.Errorf("%q matching %q: Actual:%v Expected:%v", test.pattern, test.name, got, test.want)
+		}
+	}
+
+	for _, pattern := range []string{"", "/", "a/[b"} {
+		if _, err := compileGlob(pattern); err == nil {
+			t.Errorf("compileGlob(%q) did not fail", pattern)
+		}
+	}
+}
+
+func TestIgnoreList(t *testing.T) {
+	root := &ignoreList{
+		dir:   "repo",
+		rules: parseIgnoreFile([]byte("# build output\n*.log\n/out\ndebug.log  \n")),
+	}
+
+	nested := &ignoreList{
+		parent: root,
+		dir:    "repo/src",
+		rules:  parseIgnoreFile([]byte("!keep.log\r\ngen/\n")),
+	}
+
+	tests := []struct {
+		list  *ignoreList
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{root, "repo/a.log", false, true},
+		{root, "repo/out", true, true},
+		{root, "repo/a.go", false, false},
+		{nested, "repo/src/out", true, false},
+		{nested, "repo/src/a.log", false, true},
+		{nested, "repo/src/keep.log", false, false},
+		{nested, "repo/src/gen", true, true},
+		{nested, "repo/src/gen", false, false},
+		{nil, "repo/a.log", false, false},
+	}
+
+	for _, test := range tests {
+		if got := test.list.ignored(test.path, test.isDir); got != test.want {
+			t.Errorf("ignored(%q): Actual:%v Expected:%v", test.path, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// humanSuffixes are the suffixes of the successive powers of the base of
+// human-readable numbers
+const humanSuffixes = "KMGTPE"
+
+// humanSize returns n in powers of base, 1024 or 1000, the way ls -h does:
+// "1.2K", "34M". Values are rounded up, with one decimal below 10, so that a
+// size is never understated; values below base are printed as they are.
+func humanSize(n int64, base int64) string {
+	if n < base {
+		return strconv.FormatInt(n, 10)
+	}
+
+	v := float64(n)
+	unit := -1
+
+	for v >= float64(base) && unit < len(humanSuffixes)-1 {
+		v /= float64(base)
+		unit++
+	}
+
+	if v < 10 {
+		if r := math.Ceil(v*10) / 10; r < 10 {
+			return fmt.Sprintf("%.1f%c", r, humanSuffixes[unit])
+		}
+	}
+
+	r := math.Ceil(v)
+	if r >= float64(base) && unit < len(humanSuffixes)-1 {
+		return fmt.Sprintf("1.0%c", humanSuffixes[unit+1])
+	}
+
+	return fmt.Sprintf("%.0f%c", r, humanSuffixes[unit])
+}
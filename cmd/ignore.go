@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFileNames are the ignore files honoured in every directory walked;
+// the rules of the later ones take precedence
+var ignoreFileNames = []string{".gitignore", ".ignore"}
+
+// ignoreList holds the rules of the ignore files of a directory, and links to
+// the list of the nearest directory above it that has any
+type ignoreList struct {
+	parent *ignoreList
+	dir    string
+	rules  []globPattern
+}
+
+// ignored reports whether the file at path, somewhere below the directory of
+// l, is ignored. The last rule that matches in the deepest ignore file
+// decides, so that nested ignore files and negated rules override the rules
+// before them.
+func (l *ignoreList) ignored(path string, isDir bool) bool {
+	for ; l != nil; l = l.parent {
+		rel, err := filepath.Rel(l.dir, path)
+		if err != nil {
+			continue
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		for i := len(l.rules) - 1; i >= 0; i-- {
+			if l.rules[i].match(rel, isDir) {
+				return !l.rules[i].negate
+			}
+		}
+	}
+
+	return false
+}
+
+// readIgnoreList returns the list of the ignore files in dir, given the list
+// of the directory above it, or that list itself when dir has no ignore file
+func readIgnoreList(dir string, parent *ignoreList) (*ignoreList, error) {
+	var rules []globPattern
+
+	for _, name := range ignoreFileNames {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return parent, err
+		}
+
+		rules = append(rules, parseIgnoreFile(data)...)
+	}
+
+	if len(rules) == 0 {
+		return parent, nil
+	}
+
+	return &ignoreList{parent: parent, dir: dir, rules: rules}, nil
+}
+
+// parseIgnoreFile returns the rules of an ignore file. Like git, it skips
+// blank lines, comments and malformed patterns, trims trailing spaces unless
+// they are escaped with a backslash, and negates the rules starting with "!".
+func parseIgnoreFile(data []byte) []globPattern {
+	var rules []globPattern
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+
+		for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+			line = line[:len(line)-1]
+		}
+
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		negate := line[0] == '!'
+		if negate {
+			line = line[1:]
+		}
+
+		rule, err := compileGlob(line)
+		if err != nil {
+			continue
+		}
+
+		rule.negate = negate
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/supreeth7/wcg/wc"
+)
+
+// jsonVersion is the version of the schema of the json and ndjson output.
+// Fields may be added within a version; it is raised when a field is
+// removed, renamed or changes meaning.
+const jsonVersion = 1
+
+// jsonCounts holds the counts of a file or of the total. Every field is
+// always present, counts that were not selected being null.
+type jsonCounts struct {
+	Lines         *int64 `json:"lines"`
+	Words         *int64 `json:"words"`
+	Chars         *int64 `json:"chars"`
+	Bytes         *int64 `json:"bytes"`
+	MaxLineLength *int64 `json:"max_line_length"`
+}
+
+// jsonFile holds the counts of a file, or the error that kept it from being
+// counted with null counts
+type jsonFile struct {
+	Path string `json:"path"`
+	jsonCounts
+	Error *string `json:"error"`
+}
+
+// newJSONCounts returns the counts of c selected by opts
+func newJSONCounts(opts wc.Options, c wc.Counts) jsonCounts {
+	var counts jsonCounts
+
+	if opts.Lines {
+		counts.Lines = &c.Lines
+	}
+
+	if opts.Words {
+		counts.Words = &c.Words
+	}
+
+	if opts.Chars {
+		counts.Chars = &c.Chars
+	}
+
+	if opts.Bytes {
+		counts.Bytes = &c.Bytes
+	}
+
+	if opts.MaxLineLength {
+		counts.MaxLineLength = &c.MaxLineLength
+	}
+
+	return counts
+}
+
+// newJSONFile returns the record of the given file
+func newJSONFile(opts wc.Options, name string, c wc.Counts, err error) jsonFile {
+	if err != nil {
+		msg := describeError(err)
+		return jsonFile{Path: name, Error: &msg}
+	}
+
+	return jsonFile{Path: name, jsonCounts: newJSONCounts(opts, c)}
+}
+
+// jsonFormatter prints a single json document once every file is counted:
+//
+//	{"version": 1, "files": [{"path": ..., "lines": ..., "error": null}, ...], "totals": {...}}
+type jsonFormatter struct {
+	opts wc.Options
+
+	// noTotal sets the totals to null, for --total=never
+	noTotal bool
+
+	files []jsonFile
+	w     io.Writer
+}
+
+// jsonReport is the document printed by jsonFormatter
+type jsonReport struct {
+	Version int         `json:"version"`
+	Files   []jsonFile  `json:"files"`
+	Totals  *jsonCounts `json:"totals"`
+}
+
+// file records the counts of the given file
+func (f *jsonFormatter) file(name string, c wc.Counts, err error) {
+	f.files = append(f.files, newJSONFile(f.opts, name, c, err))
+}
+
+// end prints the document. The totals are printed whether or not a total
+// row would be, unless they are never printed.
+func (f *jsonFormatter) end(total wc.Counts, showTotal bool) error {
+	report := jsonReport{
+		Version: jsonVersion,
+		Files:   f.files,
+	}
+
+	if !f.noTotal {
+		totals := newJSONCounts(f.opts, total)
+		report.Totals = &totals
+	}
+
+	if report.Files == nil {
+		report.Files = []jsonFile{}
+	}
+
+	enc := json.NewEncoder(f.w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(report)
+}
+
+// ndjsonFormatter prints a json record on its own line for every file as
+// soon as it is counted, followed by a record of the totals. Each record has
+// the schema version and a type, "file" or "total".
+type ndjsonFormatter struct {
+	opts wc.Options
+
+	// noTotal leaves out the record of the totals, for --total=never
+	noTotal bool
+
+	enc *json.Encoder
+}
+
+// ndjsonFile is the record of a file printed by ndjsonFormatter
+type ndjsonFile struct {
+	Version int    `json:"version"`
+	Type    string `json:"type"`
+	jsonFile
+}
+
+// ndjsonTotal is the record of the totals printed by ndjsonFormatter
+type ndjsonTotal struct {
+	Version int    `json:"version"`
+	Type    string `json:"type"`
+	jsonCounts
+}
+
+// file prints the record of the given file
+func (f *ndjsonFormatter) file(name string, c wc.Counts, err error) {
+	f.enc.Encode(ndjsonFile{
+		Version:  jsonVersion,
+		Type:     "file",
+		jsonFile: newJSONFile(f.opts, name, c, err),
+	})
+}
+
+// end prints the record of the totals, unless they are never printed
+func (f *ndjsonFormatter) end(total wc.Counts, showTotal bool) error {
+	if f.noTotal {
+		return nil
+	}
+
+	return f.enc.Encode(ndjsonTotal{
+		Version:    jsonVersion,
+		Type:       "total",
+		jsonCounts: newJSONCounts(f.opts, total),
+	})
+}
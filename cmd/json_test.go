@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/supreeth7/wcg/wc"
+)
+
+func TestJSONFormatter(t *testing.T) {
+	opts := wc.Options{Lines: true, Bytes: true}
+
+	var buf bytes.Buffer
+
+	f := &jsonFormatter{opts: opts, w: &buf}
+	f.file("a.txt", wc.Counts{Lines: 2, Words: 3, Bytes: 12}, nil)
+	f.file("missing", wc.Counts{}, errors.New("no such file or directory"))
+	f.end(wc.Counts{Lines: 2, Words: 3, Bytes: 12}, true)
+
+	want := `{
+  "version": 1,
+  "files": [
+    {
+      "path": "a.txt",
+      "lines": 2,
+      "words": null,
+      "chars": null,
+      "bytes": 12,
+      "max_line_length": null,
+      "error": null
+    },
+    {
+      "path": "missing",
+      "lines": null,
+      "words": null,
+      "chars": null,
+      "bytes": null,
+      "max_line_length": null,
+      "error": "No such file or directory"
+    }
+  ],
+  "totals": {
+    "lines": 2,
+    "words": null,
+    "chars": null,
+    "bytes": 12,
+    "max_line_length": null
+  }
+}
+`
+
+	if got := buf.String(); got != want {
+		t.Errorf("json output:\nActual:\n%s\nExpected:\n%s", got, want)
+	}
+}
+
+func TestNDJSONFormatter(t *testing.T) {
+	opts := wc.Options{Words: true}
+
+	var buf bytes.Buffer
+
+	f, err := newFormatter(outputOptions{output: "ndjson", showNames: true, total: totalAuto}, opts, nil, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.file("a.txt", wc.Counts{Words: 3}, nil)
+	f.end(wc.Counts{Words: 3}, false)
+
+	want := `{"version":1,"type":"file","path":"a.txt","lines":null,"words":3,"chars":null,"bytes":null,"max_line_length":null,"error":null}
+{"version":1,"type":"total","lines":null,"words":3,"chars":null,"bytes":null,"max_line_length":null}
+`
+
+	if got := buf.String(); got != want {
+		t.Errorf("ndjson output:\nActual:\n%s\nExpected:\n%s", got, want)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package cmd
+
+// openFilesLimit reports that the limit on the number of open files is not
+// known
+func openFilesLimit() (uint64, bool) {
+	return 0, false
+}
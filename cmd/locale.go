@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/supreeth7/wcg/wc"
+)
+
+// localeName returns the locale to count in: the given name when it is not
+// empty, else the first of LC_ALL, LC_CTYPE and LANG that is set
+func localeName(name string) string {
+	if name != "" {
+		return name
+	}
+
+	return firstEnv("LC_ALL", "LC_CTYPE", "LANG")
+}
+
+// numericLocaleName returns the locale to print numbers in: the first of
+// LC_ALL, LC_NUMERIC and LANG that is set
+func numericLocaleName() string {
+	return firstEnv("LC_ALL", "LC_NUMERIC", "LANG")
+}
+
+// firstEnv returns the value of the first of the given environment variables
+// that is set and not empty
+func firstEnv(names ...string) string {
+	for _, env := range names {
+		if value := os.Getenv(env); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// thousandsSeparators are the separators of groups of thousands of the
+// languages, and of the territories that differ from their language, whose
+// separator is not a comma, as the glibc locales define them; U+202F is a
+// narrow no-break space.
+var thousandsSeparators = map[string]string{
+	"de":    ".",
+	"de_CH": "'",
+	"da":    ".",
+	"el":    ".",
+	"es":    ".",
+	"es_MX": ",",
+	"id":    ".",
+	"it":    ".",
+	"it_CH": "'",
+	"nl":    ".",
+	"pt":    ".",
+	"tr":    ".",
+	"cs":    "\u202f",
+	"fi":    "\u202f",
+	"fr":    "\u202f",
+	"fr_CH": "\u202f",
+	"nb":    "\u202f",
+	"pl":    "\u202f",
+	"ru":    "\u202f",
+	"sv":    "\u202f",
+	"uk":    "\u202f",
+}
+
+// thousandsSeparator returns the separator of groups of thousands in the
+// given locale. Like printf, the C and POSIX locales, and no locale, do not
+// group digits; locales that do not encode in UTF-8 get an ASCII space
+// instead of a non-breaking one.
+func thousandsSeparator(name string) string {
+	lang := name
+	if i := strings.IndexAny(lang, ".@"); i >= 0 {
+		lang = lang[:i]
+	}
+
+	switch lang {
+	case "", "C", "POSIX":
+		return ""
+	}
+
+	sep, ok := thousandsSeparators[lang]
+	if !ok {
+		if i := strings.IndexByte(lang, '_'); i >= 0 {
+			lang = lang[:i]
+		}
+
+		if sep, ok = thousandsSeparators[lang]; !ok {
+			sep = ","
+		}
+	}
+
+	if sep == "\u202f" && wc.LocaleCharset(name) == wc.CharsetBytes {
+		sep = " "
+	}
+
+	return sep
+}
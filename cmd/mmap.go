@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+
+	"github.com/supreeth7/wcg/wc"
+)
+
+// errCannotMap is returned for files that cannot be memory-mapped, such as
+// empty files, or any file on systems other than Linux
+var errCannotMap = errors.New("file cannot be memory-mapped")
+
+// countMapped counts f, a regular file of the given size, from its mapping
+// in memory rather than by reading it. Any failure, including a file
+// truncated while it is counted, which faults when the missing pages are
+// touched, is returned as an error so that the file can be read instead.
+func (fc *fileCounter) countMapped(f *os.File, size int64) (wc.Counts, error) {
+	data, err := mapFile(f, size)
+	if err != nil {
+		return wc.Counts{}, err
+	}
+	defer unmapFile(data)
+
+	jobs := 1
+	if size >= parallelThreshold {
+		jobs = fc.jobs
+	}
+
+	var (
+		wg       sync.WaitGroup
+		ranges   = wc.SplitRanges(int64(len(data)), jobs)
+		partials = make([]wc.Partial, len(ranges))
+		errs     = make([]error, len(ranges))
+	)
+
+	for i, rg := range ranges {
+		wg.Add(1)
+		go func(i int, b []byte) {
+			defer wg.Done()
+
+			partials[i], errs[i] = countBytesSafely(b, fc.opts)
+		}(i, data[rg.Off:rg.Off+rg.Len])
+	}
+
+	wg.Wait()
+
+	whole := partials[0]
+	for i, p := range partials {
+		if errs[i] != nil {
+			return wc.Counts{}, errs[i]
+		}
+
+		if i > 0 {
+			whole = whole.Merge(p)
+		}
+	}
+
+	return whole.Total(), nil
+}
+
+// countBytesSafely counts b, turning the fault raised when b is mapped
+// memory that is no longer backed by the file into an error
+func countBytesSafely(b []byte, opts wc.Options) (p wc.Partial, err error) {
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		fault, ok := r.(interface{ Addr() uintptr })
+		if !ok {
+			panic(r)
+		}
+
+		err = fmt.Errorf("fault reading mapped file at %#x", fault.Addr())
+	}()
+
+	return wc.CountPartialBytes(b, opts), nil
+}
@@ -0,0 +1,32 @@
+//go:build linux
+// +build linux
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile maps the first size bytes of f into memory, read-only, and
+// advises the kernel that they will be read sequentially
+func mapFile(f *os.File, size int64) ([]byte, error) {
+	if size <= 0 || int64(int(size)) != size {
+		return nil, errCannotMap
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+
+	// the advice only tunes read-ahead, counting works the same without it
+	syscall.Madvise(data, syscall.MADV_SEQUENTIAL)
+
+	return data, nil
+}
+
+// unmapFile releases memory mapped by mapFile
+func unmapFile(data []byte) error {
+	return syscall.Munmap(data)
+}
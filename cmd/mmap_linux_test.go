@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/supreeth7/wcg/wc"
+)
+
+func TestCountMappedTruncated(t *testing.T) {
+	const size = 64 << 10
+
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, []byte(strings.Repeat("hello go\n", size/9)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := mapFile(f, info.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unmapFile(data)
+
+	// the pages past the new end are no longer backed by the file
+	if err := os.Truncate(path, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := wc.Options{Lines: true, Words: true, Bytes: true}
+
+	if _, err := countBytesSafely(data, opts); err == nil {
+		t.Error("countBytesSafely did not fail on a truncated mapping")
+	}
+
+	fc := newFileCounter(opts, 1)
+
+	if _, err := fc.countMapped(f, info.Size()); err == nil {
+		t.Error("countMapped did not fail on a truncated file")
+	}
+
+	want, err := fc.count(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fc.mmap = true
+
+	got, err := fc.count(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != want {
+		t.Errorf("mapped: Actual:%+v Expected:%+v", got, want)
+	}
+}
+
+func TestCountMappedManyJobs(t *testing.T) {
+	// a file large enough to be split, with more jobs than would fit in it
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, []byte(strings.Repeat("hello go\n", parallelThreshold/9+12)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := wc.Options{Lines: true, Words: true, Bytes: true}
+	fc := newFileCounter(opts, 100000)
+
+	expected, err := fc.count(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fc.mmap = true
+
+	actual, err := fc.count(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual != expected {
+		t.Errorf("mapped: Actual:%+v Expected:%+v", actual, expected)
+	}
+}
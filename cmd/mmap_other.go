@@ -0,0 +1,16 @@
+//go:build !linux
+// +build !linux
+
+package cmd
+
+import "os"
+
+// mapFile always fails outside Linux, so that files are read instead
+func mapFile(f *os.File, size int64) ([]byte, error) {
+	return nil, errCannotMap
+}
+
+// unmapFile is never called outside Linux
+func unmapFile(data []byte) error {
+	return nil
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxHumanWidth is the widest a human-readable count can be, like "1023K"
+// or "1,023"
+const maxHumanWidth = 5
+
+// numberFormat selects how the plain output prints counts. The zero
+// numberFormat prints them as decimal numbers.
+type numberFormat struct {
+	// human prints counts in powers of 1000, like 1.2K, and byte counts in
+	// powers of bytesBase
+	human     bool
+	bytesBase int64
+
+	// sep separates groups of thousands when not empty
+	sep string
+}
+
+// format returns the count n, a byte count when isBytes is set
+func (nf numberFormat) format(n int64, isBytes bool) string {
+	if nf.human {
+		base := int64(1000)
+		if isBytes {
+			base = nf.bytesBase
+		}
+
+		if n >= base {
+			return humanSize(n, base)
+		}
+	}
+
+	return groupThousands(strconv.FormatInt(n, 10), nf.sep)
+}
+
+// width returns the width of the columns printing counts up to digits digits
+// long
+func (nf numberFormat) width(digits int) int {
+	width := digits
+	if nf.sep != "" {
+		width += (digits - 1) / 3 * utf8.RuneCountInString(nf.sep)
+	}
+
+	if nf.human && width > maxHumanWidth {
+		width = maxHumanWidth
+	}
+
+	return width
+}
+
+// groupThousands inserts sep between the groups of three digits of the
+// decimal number s
+func groupThousands(s, sep string) string {
+	if sep == "" || len(s) <= 3 {
+		return s
+	}
+
+	var b strings.Builder
+
+	first := len(s) % 3
+	if first == 0 {
+		first = 3
+	}
+
+	b.WriteString(s[:first])
+
+	for i := first; i < len(s); i += 3 {
+		b.WriteString(sep)
+		b.WriteString(s[i : i+3])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import "testing"
+
+func TestNumberFormat(t *testing.T) {
+	tests := []struct {
+		nf      numberFormat
+		n       int64
+		isBytes bool
+		want    string
+	}{
+		{numberFormat{}, 1234567, false, "1234567"},
+		{numberFormat{sep: ","}, 123, false, "123"},
+		{numberFormat{sep: ","}, 1234, false, "1,234"},
+		{numberFormat{sep: "."}, 1234567890, false, "1.234.567.890"},
+		{numberFormat{sep: "\u202f"}, 123456, false, "123\u202f456"},
+		{numberFormat{human: true, bytesBase: 1024}, 999, false, "999"},
+		{numberFormat{human: true, bytesBase: 1024}, 1200, false, "1.2K"},
+		{numberFormat{human: true, bytesBase: 1024}, 1000, true, "1000"},
+		{numberFormat{human: true, bytesBase: 1024}, 3565158, true, "3.4M"},
+		{numberFormat{human: true, bytesBase: 1000}, 3565158, true, "3.6M"},
+		{numberFormat{human: true, bytesBase: 1024, sep: ","}, 1023, true, "1,023"},
+	}
+
+	for _, test := range tests {
+		if got := test.nf.format(test.n, test.isBytes); got != test.want {
+			t.Errorf("%+v formatting %d: Actual:%q Expected:%q", test.nf, test.n, got, test.want)
+		}
+	}
+
+	if got := (numberFormat{sep: "\u202f"}).width(7); got != 9 {
+		t.Errorf("width of 7 grouped digits: Actual:%d Expected:%d", got, 9)
+	}
+
+	if got := (numberFormat{human: true}).width(10); got != maxHumanWidth {
+		t.Errorf("width of 10 digits in human form: Actual:%d Expected:%d", got, maxHumanWidth)
+	}
+}
+
+func TestThousandsSeparator(t *testing.T) {
+	tests := map[string]string{
+		"":                 "",
+		"C":                "",
+		"POSIX":            "",
+		"C.UTF-8":          "",
+		"en_US.UTF-8":      ",",
+		"de_DE.UTF-8":      ".",
+		"de_CH.UTF-8":      "'",
+		"fr_FR.UTF-8":      "\u202f",
+		"fr_FR.ISO-8859-1": " ",
+		"es_MX.UTF-8":      ",",
+		"es_ES.UTF-8@euro": ".",
+		"xx_YY.UTF-8":      ",",
+	}
+
+	for name, want := range tests {
+		if got := thousandsSeparator(name); got != want {
+			t.Errorf("thousandsSeparator(%q): Actual:%q Expected:%q", name, got, want)
+		}
+	}
+}
@@ -17,15 +17,16 @@ limitations under the License.
 package cmd
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"io/fs"
 	"io/ioutil"
 	"os"
+	"runtime"
 	"strings"
-	"unicode/utf8"
 
 	"github.com/spf13/cobra"
+	"github.com/supreeth7/wcg/wc"
 )
 
 const helpText = `
@@ -41,9 +42,65 @@ const helpText = `
   -c, --bytes        	print the byte counts
   -m, --chars        	print the character counts
   -l, --lines        	print the newline counts
+  	--logical-lines	count a final line without a trailing newline as
+                       	a line as well
+  	--locale=NAME	count words and characters in the locale NAME
+                       	instead of LC_ALL, LC_CTYPE or LANG; C and
+                       	POSIX count bytes, UTF-8 locales characters
+  -j, --jobs=N       	count up to N files at once, and files larger than
+                       	16 MiB in N ranges; defaults to the number of CPUs
+  	--mmap         	count regular files from their mapping in memory
+                       	instead of reading them, on Linux
   	--files0-from=F	read input from the files specified by
                        	NULL-terminated names in file F;
                        	If F is - then read names from standard input
+  -r, --recursive    	count every regular file under the directories
+                       	given, and print a total
+  	--max-depth=N  	descend at most N levels of directories below
+                       	each FILE
+  -P, --no-dereference	never follow symbolic links while walking
+                       	directories (the default)
+  -H, --dereference-command-line
+                       	follow the symbolic links given as FILE only
+  	--dereference  	follow every symbolic link; it has no short
+                       	option, as -L is --max-line-length
+  	--one-file-system	skip directories on other file systems
+  	--include=GLOB 	only count the files matching GLOB while walking
+                       	directories; "**" matches any number of
+                       	directories, and a GLOB with no slash matches
+                       	file names at any depth
+  	--exclude=GLOB 	skip the files and directories matching GLOB
+  	--exclude-from=F	skip the files matching the GLOBs listed in F,
+                       	one per line
+  	--use-ignore-files	skip the files ignored by the .gitignore and
+                       	.ignore files found while walking, and .git
+  	--skip-generated	skip generated Go files, files that have NUL
+                       	bytes or do not look like text, and vendor and
+                       	node_modules directories while walking
+  	--verbose      	list the files skipped and why on standard error
+  	--output=FORMAT	print the counts as plain columns (the default),
+                       	as a json document, as ndjson records streamed
+                       	as each file is counted, or as a csv, tsv or
+                       	markdown table
+  	--format=TMPL  	print every row with the Go text/template TMPL,
+                       	such as '{{.Lines}}\t{{.Path}}'; the fields are
+                       	Path, Lines, Words, Chars, Bytes, MaxLineLength
+                       	and Total, which holds the total counts, and
+                       	the functions pad, padRight, human, humanSI and
+                       	percent; every count is computed unless some
+                       	are selected
+  	--total-format=TMPL
+                       	print the total row with TMPL instead
+  	--header=TMPL  	print a header with TMPL before the rows
+  	--total=WHEN   	when to print a line with total counts;
+                       	WHEN can be: auto, always, only, never
+  	--human[=BASE] 	print counts like 1.2K and 3.4M in powers of
+                       	1000, and byte counts in powers of 1024 (iec,
+                       	the default) or of 1000 (si)
+  	--grouping     	separate groups of thousands in counts, as
+                       	LC_ALL, LC_NUMERIC or LANG does
+  	--thousands-sep=SEP
+                       	separate groups of thousands with SEP
   -L, --max-line-length  print the maximum display width
   -w, --words        	print the word counts
   	--help 	display this help and exit
@@ -54,71 +111,147 @@ const helpText = `
  or available locally via: info '(coreutils) wc invocation'
  `
 
+// programName prefixes the errors printed on the standard error
+const programName = "wcg"
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:     "wcg",
+	Use:     programName,
 	Short:   "A clone of the famous linux wc command",
 	Long:    "Prints newline, word, and byte counts for each FILE, and a total line if more than one FILE is specified",
 	Version: "1.1.0",
 
+	SilenceErrors: true,
+
 	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
 
 		isBytes, _ := cmd.Flags().GetBool("bytes")
 		isChars, _ := cmd.Flags().GetBool("chars")
 		isLines, _ := cmd.Flags().GetBool("lines")
 		isWords, _ := cmd.Flags().GetBool("words")
 		isMaxLength, _ := cmd.Flags().GetBool("max-line-length")
+		isLogicalLines, _ := cmd.Flags().GetBool("logical-lines")
+		locale, _ := cmd.Flags().GetString("locale")
+		jobs, _ := cmd.Flags().GetInt("jobs")
+		useMmap, _ := cmd.Flags().GetBool("mmap")
+		recursive, _ := cmd.Flags().GetBool("recursive")
+		skipGenerated, _ := cmd.Flags().GetBool("skip-generated")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		format, _ := cmd.Flags().GetString("format")
+		totalName, _ := cmd.Flags().GetString("total")
+
+		opts := wc.Options{
+			Bytes:         isBytes,
+			Chars:         isChars,
+			Lines:         isLines,
+			Words:         isWords,
+			MaxLineLength: isMaxLength,
+		}
+
+		switch {
+		case opts.Any():
+		case format != "":
+			// a template may print any count
+			opts = opts.All()
+		default:
+			opts = defaultCounts
+		}
+
+		opts.LogicalLines = isLogicalLines
+		opts.Charset = wc.LocaleCharset(localeName(locale))
+		_, opts.POSIXSpaces = os.LookupEnv("POSIXLY_CORRECT")
+
+		if jobs < 1 {
+			jobs = runtime.GOMAXPROCS(0)
+		}
+
+		counter := newFileCounter(opts, jobs)
+		counter.mmap = useMmap
+
+		w, err := newWalker(cmd)
+		if err != nil {
+			return err
+		}
+
+		files0From, _ := cmd.Flags().GetString("files0-from")
+
+		files := args
+		showNames := len(args) > 0
 
 		var (
-			fileName string
-			data     string
+			total   wc.Counts
+			failed  bool
+			walked  bool
+			invalid []error
 		)
 
-		if len(args) >= 1 {
-			fileInfo, err := checkIfFileExists(args[0])
-			if err != nil {
-				return err
+		if files0From != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("extra operand %q\nfile operands cannot be combined with --files0-from", args[0])
 			}
 
-			fileData, err := ConvertFileToString(args[0])
+			names, reasons, err := readFilesFrom(files0From)
 			if err != nil {
 				return err
 			}
 
-			fileName = fileInfo.Name()
-			data = fileData
+			files = names
+			invalid = reasons
+			showNames = true
+		} else if len(files) == 0 {
+			files = []string{"-"}
+		}
 
-		} else {
-			scanner := bufio.NewScanner(os.Stdin)
-			for scanner.Scan() {
-				text := scanner.Text()
-				data += text + "\n"
+		if recursive || skipGenerated {
+			w.report = func(path string, err error) {
+				printError(path, err)
+				failed = true
 			}
+
+			if verbose {
+				w.skipped = printSkipped
+			}
+
+			files, walked = w.expand(files)
 		}
 
-		switch {
-		case isBytes:
-			result := GetByteCount(data)
-			printResult(result, fileName)
-		case isChars:
-			result := GetCharacterCount(data)
-			printResult(result, fileName)
-		case isLines:
-			result := GetLineCount(data)
-			printResult(result, fileName)
-		case isWords:
-			result := GetWordCount(data)
-			printResult(result, fileName)
-		case isMaxLength:
-			result := GetMaxLineLength(data)
-			printResult(result, fileName)
-		default:
-			lines := GetLineCount(data)
-			words := GetWordCount(data)
-			bytes := GetByteCount(data)
-			chars := GetCharacterCount(data)
-			maxLinLength := GetMaxLineLength(data)
-			fmt.Printf("%d %d %d %d %d%s\n", lines, words, bytes, chars, maxLinLength, fileName)
+		mode, err := parseTotalMode(totalName)
+		if err != nil {
+			return err
+		}
+
+		out, err := newOutput(cmd, opts, files, showNames, mode)
+		if err != nil {
+			return err
+		}
+
+		counter.countAll(files, func(file string, result wc.Counts, err error) {
+			if file == invalidName {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", programName, invalid[0])
+				invalid = invalid[1:]
+				failed = true
+				return
+			}
+
+			if err != nil {
+				printError(file, err)
+				failed = true
+			} else {
+				total.Add(result)
+			}
+
+			if mode != totalOnly {
+				out.file(file, result, err)
+			}
+		})
+
+		if err := out.end(total, mode.showTotal(len(files), walked)); err != nil {
+			return err
+		}
+
+		if failed {
+			return errFailed
 		}
 
 		return nil
@@ -127,10 +260,22 @@ var rootCmd = &cobra.Command{
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Errors are printed like wc does, and any error, including a file that could
+// not be counted, exits with status 1.
 func Execute() {
-	cobra.CheckErr(rootCmd.Execute())
+	if err := rootCmd.Execute(); err != nil {
+		if err != errFailed {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", programName, err)
+		}
+
+		os.Exit(1)
+	}
 }
 
+// errFailed is returned once at least one file could not be counted; the
+// reason has already been printed for each of them
+var errFailed = errors.New("some files could not be counted")
+
 func init() {
 	rootCmd.SetHelpTemplate(helpText)
 
@@ -140,11 +285,194 @@ func init() {
 	rootCmd.Flags().BoolP("lines", "l", false, "prints the line count")
 	rootCmd.Flags().BoolP("words", "w", false, "prints the word count")
 	rootCmd.Flags().BoolP("max-line-length", "L", false, "prints the maximum line length count")
+	rootCmd.Flags().Bool("logical-lines", false, "counts a final line without a trailing newline as a line")
+	rootCmd.Flags().String("locale", "", "counts words and characters in the given locale instead of LC_ALL, LC_CTYPE or LANG")
+	rootCmd.Flags().IntP("jobs", "j", 0, "counts files, and ranges of large files, with the given number of goroutines; defaults to GOMAXPROCS")
+	rootCmd.Flags().Bool("mmap", false, "counts regular files from their mapping in memory instead of reading them")
+	rootCmd.Flags().String("files0-from", "", "reads the NUL-terminated file names to count from the given file")
+	rootCmd.Flags().BoolP("recursive", "r", false, "counts every regular file under the given directories")
+	rootCmd.Flags().Int("max-depth", -1, "descends at most the given number of levels of directories")
+	rootCmd.Flags().BoolP("no-dereference", "P", false, "never follows symbolic links while walking directories")
+	rootCmd.Flags().BoolP("dereference-command-line", "H", false, "follows the symbolic links given on the command line only")
+	rootCmd.Flags().Bool("dereference", false, "follows every symbolic link while walking directories")
+	rootCmd.Flags().Bool("one-file-system", false, "skips directories on other file systems while walking directories")
+	rootCmd.Flags().StringArray("include", nil, "only counts the files matching the given pattern while walking directories")
+	rootCmd.Flags().StringArray("exclude", nil, "skips the files and directories matching the given pattern while walking directories")
+	rootCmd.Flags().StringArray("exclude-from", nil, "skips the files and directories matching the patterns listed in the given file")
+	rootCmd.Flags().Bool("use-ignore-files", false, "skips the files ignored by the .gitignore and .ignore files found while walking directories")
+	rootCmd.Flags().Bool("skip-generated", false, "skips generated and binary files, and vendored directories while walking directories")
+	rootCmd.Flags().Bool("verbose", false, "lists the files skipped by --skip-generated on the standard error")
+	rootCmd.Flags().String("output", "plain", "prints the counts as plain columns, json, ndjson, csv, tsv or markdown")
+	rootCmd.Flags().String("format", "", "prints every row with the given Go template")
+	rootCmd.Flags().String("total-format", "", "prints the total row with the given Go template")
+	rootCmd.Flags().String("header", "", "prints a header with the given Go template")
+	rootCmd.Flags().String("human", "", "prints counts like 1.2K and 3.4M, byte counts in powers of 1024 (iec) or 1000 (si)")
+	rootCmd.Flags().Lookup("human").NoOptDefVal = "iec"
+	rootCmd.Flags().Bool("grouping", false, "separates groups of thousands as LC_NUMERIC does")
+	rootCmd.Flags().String("thousands-sep", "", "separates groups of thousands with the given separator")
+	rootCmd.Flags().String("total", "auto", "prints the total row when there are several files (auto), always, only or never")
+}
+
+// walkFlags only apply to the directories walked by --recursive
+var walkFlags = []string{
+	"max-depth",
+	"dereference-command-line",
+	"dereference",
+	"one-file-system",
+	"include",
+	"exclude",
+	"exclude-from",
+	"use-ignore-files",
+}
+
+// newWalker returns a walker with the recursion flags given on the command
+// line. The flags do not keep their order, so when several symbolic link
+// policies are given the one following the most links wins.
+func newWalker(cmd *cobra.Command) (*walker, error) {
+	recursive, _ := cmd.Flags().GetBool("recursive")
+
+	if !recursive {
+		for _, name := range walkFlags {
+			if cmd.Flags().Changed(name) {
+				return nil, fmt.Errorf("--%s requires --recursive", name)
+			}
+		}
+	}
+
+	skipGenerated, _ := cmd.Flags().GetBool("skip-generated")
+	maxDepth, _ := cmd.Flags().GetInt("max-depth")
+	dereference, _ := cmd.Flags().GetBool("dereference")
+	commandLine, _ := cmd.Flags().GetBool("dereference-command-line")
+	oneFileSystem, _ := cmd.Flags().GetBool("one-file-system")
+	include, _ := cmd.Flags().GetStringArray("include")
+	exclude, _ := cmd.Flags().GetStringArray("exclude")
+	excludeFrom, _ := cmd.Flags().GetStringArray("exclude-from")
+	ignoreFiles, _ := cmd.Flags().GetBool("use-ignore-files")
+
+	for _, file := range excludeFrom {
+		patterns, err := readPatterns(file)
+		if err != nil {
+			return nil, err
+		}
+
+		exclude = append(exclude, patterns...)
+	}
+
+	w := &walker{
+		recursive:     recursive,
+		skipGenerated: skipGenerated,
+		maxDepth:      maxDepth,
+		oneFileSystem: oneFileSystem,
+		ignoreFiles:   ignoreFiles,
+	}
+
+	var err error
+
+	if w.include, err = compileGlobs(include); err != nil {
+		return nil, err
+	}
+
+	if w.exclude, err = compileGlobs(exclude); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case dereference:
+		w.symlinks = followAll
+	case commandLine:
+		w.symlinks = followCommandLine
+	}
+
+	return w, nil
 }
 
-//printResult prints a formatted result with the given arguments
-func printResult(n int, file string) {
-	fmt.Printf("%d %s\n", n, file)
+// newOutput returns the formatter printing the counts to the standard output
+// as the --output, --format, --total-format and --header flags select
+func newOutput(cmd *cobra.Command, opts wc.Options, files []string, showNames bool, total totalMode) (formatter, error) {
+	output, _ := cmd.Flags().GetString("output")
+	format, _ := cmd.Flags().GetString("format")
+	totalFormat, _ := cmd.Flags().GetString("total-format")
+	header, _ := cmd.Flags().GetString("header")
+
+	switch {
+	case format == "" && (totalFormat != "" || header != ""):
+		return nil, fmt.Errorf("--total-format and --header require --format")
+	case format == "":
+		numbers, err := newNumberFormat(cmd)
+		if err != nil {
+			return nil, err
+		}
+
+		o := outputOptions{
+			output:    output,
+			showNames: showNames,
+			total:     total,
+			numbers:   numbers,
+		}
+
+		return newFormatter(o, opts, files, os.Stdout)
+	case cmd.Flags().Changed("output"):
+		return nil, fmt.Errorf("--format cannot be combined with --output")
+	}
+
+	f, err := newTemplateFormatter(format, totalFormat, header, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// newNumberFormat returns the format of the counts of the plain output
+// selected by the --human, --grouping and --thousands-sep flags
+func newNumberFormat(cmd *cobra.Command) (numberFormat, error) {
+	human, _ := cmd.Flags().GetString("human")
+	grouping, _ := cmd.Flags().GetBool("grouping")
+	sep, _ := cmd.Flags().GetString("thousands-sep")
+
+	var nf numberFormat
+
+	switch human {
+	case "":
+	case "iec":
+		nf.human, nf.bytesBase = true, 1024
+	case "si":
+		nf.human, nf.bytesBase = true, 1000
+	default:
+		return nf, fmt.Errorf("invalid argument %q for \"--human\"\nValid arguments are: iec, si", human)
+	}
+
+	switch {
+	case cmd.Flags().Changed("thousands-sep"):
+		nf.sep = sep
+	case grouping:
+		nf.sep = thousandsSeparator(numericLocaleName())
+	}
+
+	return nf, nil
+}
+
+// compileGlobs compiles the patterns given on the command line
+func compileGlobs(patterns []string) ([]globPattern, error) {
+	globs := make([]globPattern, 0, len(patterns))
+
+	for _, pattern := range patterns {
+		g, err := compileGlob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+
+		globs = append(globs, g)
+	}
+
+	return globs, nil
+}
+
+// defaultCounts selects the counts printed when none is given, like wc -lwc
+var defaultCounts = wc.Options{
+	Lines: true,
+	Words: true,
+	Bytes: true,
 }
 
 func checkIfFileExists(fileName string) (fs.FileInfo, error) {
@@ -157,7 +485,35 @@ func checkIfFileExists(fileName string) (fs.FileInfo, error) {
 	return fileInfo, nil
 }
 
-//ConvertFileToString converts the file data into a code readable string
+// printError reports on the standard error that the given file could not be
+// counted, like "wcg: file: No such file or directory"
+func printError(file string, err error) {
+	fmt.Fprintf(os.Stderr, "%s: %s: %s\n", programName, file, describeError(err))
+}
+
+// printSkipped reports on the standard error that the given file was not
+// counted, like "wcg: skipping vendor: vendored directory"
+func printSkipped(file, reason string) {
+	fmt.Fprintf(os.Stderr, "%s: skipping %s: %s\n", programName, file, reason)
+}
+
+// describeError returns the reason of a file system error without the
+// operation and path that PathError adds, capitalized like strerror(3)
+func describeError(err error) string {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+
+	msg := err.Error()
+	if msg == "" {
+		return msg
+	}
+
+	return strings.ToUpper(msg[:1]) + msg[1:]
+}
+
+// ConvertFileToString converts the file data into a code readable string
 func ConvertFileToString(file string) (string, error) {
 	data, err := ioutil.ReadFile(file)
 	if err != nil {
@@ -167,62 +523,33 @@ func ConvertFileToString(file string) (string, error) {
 	return string(data), nil
 }
 
-//GetByteCount returns the total bytes from the given argument string
+// GetByteCount returns the total bytes from the given argument string
 func GetByteCount(data string) int {
-	return len(data)
+	return int(wc.CountString(data, wc.Options{Bytes: true}).Bytes)
 }
 
-//GetCharacterCount returns the total characters from the given argument string
+// GetCharacterCount returns the total characters from the given argument string
 func GetCharacterCount(data string) int {
-	return utf8.RuneCount([]byte(data))
+	return int(wc.CountString(data, wc.Options{Chars: true}).Chars)
 }
 
-//GetLineCount returns the total lines from the given argument string
+// GetLineCount returns the total newline characters from the given argument string
 func GetLineCount(data string) int {
-	scanner := bufio.NewScanner(strings.NewReader(data))
-	lines := 0
-
-	for scanner.Scan() {
-		lines++
-	}
+	return int(wc.CountString(data, wc.Options{Lines: true}).Lines)
+}
 
-	return lines
+// GetLogicalLineCount returns the total lines from the given argument string,
+// including a final line that is not terminated by a newline
+func GetLogicalLineCount(data string) int {
+	return int(wc.CountString(data, wc.Options{Lines: true, LogicalLines: true}).Lines)
 }
 
-//GetWordCount returns the total word count from the given argument string
+// GetWordCount returns the total word count from the given argument string
 func GetWordCount(data string) int {
-	scanner := bufio.NewScanner(strings.NewReader(data))
-	scanner.Split(bufio.ScanWords)
-
-	words := 0
-
-	for scanner.Scan() {
-		words++
-	}
-
-	return words
+	return int(wc.CountString(data, wc.Options{Words: true}).Words)
 }
 
-//GetMaxLineLength returns the maximum line length from the given argument string
+// GetMaxLineLength returns the maximum line length from the given argument string
 func GetMaxLineLength(data string) int {
-	scanner := bufio.NewScanner(strings.NewReader(data))
-
-	var (
-		line        string
-		length      int
-		longestLine int
-	)
-
-	for scanner.Scan() {
-
-		line = scanner.Text()
-
-		length = utf8.RuneCountInString(line)
-
-		if length > longestLine {
-			longestLine = length
-		}
-	}
-
-	return longestLine
+	return int(wc.CountString(data, wc.Options{MaxLineLength: true}).MaxLineLength)
 }
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/supreeth7/wcg/wc"
+)
+
+// csvFormatter prints a table of the counts as comma or tab separated
+// values: a header row naming the selected counts and "path", a row for every
+// file counted and the total row. File names holding the separator, quotes
+// or newlines are quoted as RFC 4180 describes.
+type csvFormatter struct {
+	opts    wc.Options
+	w       *csv.Writer
+	started bool
+}
+
+// newCSVFormatter returns a csvFormatter writing to w with the given
+// separator
+func newCSVFormatter(opts wc.Options, comma rune, w io.Writer) *csvFormatter {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+
+	return &csvFormatter{opts: opts, w: cw}
+}
+
+// file prints the row of the given file; files that could not be counted are
+// only reported on the standard error
+func (f *csvFormatter) file(name string, c wc.Counts, err error) {
+	if err == nil {
+		f.row(c, name)
+	}
+}
+
+// end prints the total row and flushes the table
+func (f *csvFormatter) end(total wc.Counts, showTotal bool) error {
+	if showTotal {
+		f.row(total, "total")
+	}
+
+	f.start()
+	f.w.Flush()
+
+	return f.w.Error()
+}
+
+// row prints a row of counts, after the header if it is the first
+func (f *csvFormatter) row(c wc.Counts, name string) {
+	f.start()
+	f.w.Write(append(formatValues(values(f.opts, c)), name))
+	f.w.Flush()
+}
+
+// start prints the header once
+func (f *csvFormatter) start() {
+	if !f.started {
+		f.started = true
+		f.w.Write(append(columnNames(f.opts), "path"))
+	}
+}
+
+// markdownFormatter prints a Markdown table of the counts, right-aligned,
+// with a header row naming the selected counts and "path", and a bold total
+// row
+type markdownFormatter struct {
+	opts    wc.Options
+	w       io.Writer
+	started bool
+}
+
+// file prints the row of the given file; files that could not be counted are
+// only reported on the standard error
+func (f *markdownFormatter) file(name string, c wc.Counts, err error) {
+	if err == nil {
+		f.row(formatValues(values(f.opts, c)), markdownEscape(name))
+	}
+}
+
+// end prints the total row in bold
+func (f *markdownFormatter) end(total wc.Counts, showTotal bool) error {
+	if showTotal {
+		cells := formatValues(values(f.opts, total))
+		for i, cell := range cells {
+			cells[i] = "**" + cell + "**"
+		}
+
+		f.row(cells, "**total**")
+	}
+
+	f.start()
+
+	return nil
+}
+
+// row prints a row of cells followed by the file name, after the header if
+// it is the first
+func (f *markdownFormatter) row(cells []string, name string) {
+	f.start()
+	io.WriteString(f.w, "| "+strings.Join(append(cells, name), " | ")+" |\n")
+}
+
+// start prints the header and the row setting the alignment of the columns
+// once
+func (f *markdownFormatter) start() {
+	if f.started {
+		return
+	}
+
+	f.started = true
+
+	names := columnNames(f.opts)
+	align := make([]string, len(names))
+	for i := range align {
+		align[i] = "---:"
+	}
+
+	io.WriteString(f.w, "| "+strings.Join(append(names, "path"), " | ")+" |\n")
+	io.WriteString(f.w, "| "+strings.Join(append(align, "---"), " | ")+" |\n")
+}
+
+// markdownReplacer escapes the characters of a file name that would end a
+// table cell or be taken for markup
+var markdownReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	"|", `\|`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"<", "&lt;",
+	"\r\n", "<br>",
+	"\n", "<br>",
+	"\r", "<br>",
+)
+
+// markdownEscape returns the file name as the text of a table cell
+func markdownEscape(name string) string {
+	return markdownReplacer.Replace(name)
+}
+
+// formatValues returns the given counts as decimal numbers
+func formatValues(values []int64) []string {
+	cells := make([]string, len(values))
+	for i, n := range values {
+		cells[i] = strconv.FormatInt(n, 10)
+	}
+
+	return cells
+}
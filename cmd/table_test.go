@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/supreeth7/wcg/wc"
+)
+
+func TestTableFormatters(t *testing.T) {
+	opts := wc.Options{Lines: true, Bytes: true}
+
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"csv", "lines,bytes,path\n" +
+			"1,4,a.txt\n" +
+			"2,8,\"b, \"\"c\"\"\td\ne\"\n" +
+			"3,12,total\n"},
+		{"tsv", "lines\tbytes\tpath\n" +
+			"1\t4\ta.txt\n" +
+			"2\t8\t\"b, \"\"c\"\"\td\ne\"\n" +
+			"3\t12\ttotal\n"},
+		{"markdown", "| lines | bytes | path |\n" +
+			"| ---: | ---: | --- |\n" +
+			"| 1 | 4 | a.txt |\n" +
+			"| 2 | 8 | b, \"c\"\td<br>e |\n" +
+			"| **3** | **12** | **total** |\n"},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+
+		f, err := newFormatter(outputOptions{output: test.output, showNames: true, total: totalAuto}, opts, nil, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		f.file("a.txt", wc.Counts{Lines: 1, Bytes: 4}, nil)
+		f.file("b, \"c\"\td\ne", wc.Counts{Lines: 2, Bytes: 8}, nil)
+		f.end(wc.Counts{Lines: 3, Bytes: 12}, true)
+
+		if got := buf.String(); got != test.want {
+			t.Errorf("%s output:\nActual:\n%q\nExpected:\n%q", test.output, got, test.want)
+		}
+	}
+}
+
+func TestMarkdownEscape(t *testing.T) {
+	if got, want := markdownEscape("a|b_*c*`d`\\<e>"), "a\\|b\\_\\*c\\*\\`d\\`\\\\&lt;e>"; got != want {
+		t.Errorf("markdownEscape: Actual:%q Expected:%q", got, want)
+	}
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/supreeth7/wcg/wc"
+)
+
+// templateFuncs are the functions available to the --format, --total-format
+// and --header templates, besides the builtin ones
+var templateFuncs = template.FuncMap{
+	// pad right-aligns a value to the given width, like the count columns
+	"pad": func(width int, v interface{}) string {
+		s := fmt.Sprint(v)
+		return strings.Repeat(" ", padding(width, s)) + s
+	},
+
+	// padRight left-aligns a value to the given width
+	"padRight": func(width int, v interface{}) string {
+		s := fmt.Sprint(v)
+		return s + strings.Repeat(" ", padding(width, s))
+	},
+
+	// human prints a count in powers of 1024, like 1.2K or 34M
+	"human": func(n int64) string {
+		return humanSize(n, 1024)
+	},
+
+	// humanSI prints a count in powers of 1000
+	"humanSI": func(n int64) string {
+		return humanSize(n, 1000)
+	},
+
+	// percent prints part as a percentage of total, like 12.5%
+	"percent": func(part, total int64) string {
+		if total == 0 {
+			return "0.0%"
+		}
+
+		return fmt.Sprintf("%.1f%%", float64(part)*100/float64(total))
+	},
+}
+
+// padding returns the number of spaces that pad s to width characters
+func padding(width int, s string) int {
+	if n := width - utf8.RuneCountInString(s); n > 0 {
+		return n
+	}
+
+	return 0
+}
+
+// templateRow is the data of the templates: the counts of a file, or of the
+// total for the --total-format and --header templates, and the total
+type templateRow struct {
+	Path string
+	wc.Counts
+	Total wc.Counts
+}
+
+// templateFormatter prints every row with a user-defined template. The rows
+// are printed once every file is counted, so that the total is known to
+// every row.
+type templateFormatter struct {
+	row, total, header *template.Template
+
+	rows []templateRow
+	w    io.Writer
+}
+
+// newTemplateFormatter returns a templateFormatter printing the rows with
+// the format template, the total row with the totalFormat template, or the
+// format template when it is empty, and a header with the header template
+// when it is not empty
+func newTemplateFormatter(format, totalFormat, header string, w io.Writer) (*templateFormatter, error) {
+	f := &templateFormatter{w: w}
+
+	var err error
+
+	if f.row, err = parseTemplate("format", format); err != nil {
+		return nil, err
+	}
+
+	f.total = f.row
+	if totalFormat != "" {
+		if f.total, err = parseTemplate("total-format", totalFormat); err != nil {
+			return nil, err
+		}
+	}
+
+	if header != "" {
+		if f.header, err = parseTemplate("header", header); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+// parseTemplate parses the template given to the flag of the given name. The
+// backslash escapes \t, \n and \\ in its text, outside of actions, are
+// replaced so that they can be given from a shell.
+func parseTemplate(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Funcs(templateFuncs).Parse(unescapeText(text))
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s template: %v", name, strings.TrimPrefix(err.Error(), "template: "))
+	}
+
+	return t, nil
+}
+
+// textEscapes replaces the backslash escapes of the text of a template
+var textEscapes = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n")
+
+// unescapeText replaces the backslash escapes of text outside of the
+// actions between "{{" and "}}"
+func unescapeText(text string) string {
+	var b strings.Builder
+
+	for {
+		start := strings.Index(text, "{{")
+		if start < 0 {
+			b.WriteString(textEscapes.Replace(text))
+			return b.String()
+		}
+
+		b.WriteString(textEscapes.Replace(text[:start]))
+		text = text[start:]
+
+		end := strings.Index(text, "}}")
+		if end < 0 {
+			b.WriteString(text)
+			return b.String()
+		}
+
+		b.WriteString(text[:end+2])
+		text = text[end+2:]
+	}
+}
+
+// file keeps the row of the given file; files that could not be counted are
+// only reported on the standard error
+func (f *templateFormatter) file(name string, c wc.Counts, err error) {
+	if err == nil {
+		f.rows = append(f.rows, templateRow{Path: name, Counts: c})
+	}
+}
+
+// end prints the header, the rows and the total row, each followed by a
+// newline
+func (f *templateFormatter) end(total wc.Counts, showTotal bool) error {
+	totalRow := templateRow{Path: "total", Counts: total, Total: total}
+
+	if f.header != nil {
+		if err := f.print(f.header, totalRow); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range f.rows {
+		row.Total = total
+		if err := f.print(f.row, row); err != nil {
+			return err
+		}
+	}
+
+	if showTotal {
+		return f.print(f.total, totalRow)
+	}
+
+	return nil
+}
+
+// print prints a row with the template t
+func (f *templateFormatter) print(t *template.Template, row templateRow) error {
+	var b strings.Builder
+
+	if err := t.Execute(&b, row); err != nil {
+		return fmt.Errorf("cannot print --%s template: %v", t.Name(), strings.TrimPrefix(err.Error(), "template: "))
+	}
+
+	b.WriteByte('\n')
+
+	_, err := io.WriteString(f.w, b.String())
+
+	return err
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/supreeth7/wcg/wc"
+)
+
+func TestTemplateFormatter(t *testing.T) {
+	var buf bytes.Buffer
+
+	f, err := newTemplateFormatter(
+		`{{pad 3 .Lines}}\t{{padRight 5 .Path}}|{{percent .Lines .Total.Lines}}`,
+		`{{pad 3 .Lines}}\t{{.Path}} {{human .Bytes}}`,
+		`{{"{{lines}}"}}\tpath\\n`,
+		&buf,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.file("a.go", wc.Counts{Lines: 30, Bytes: 1000}, nil)
+	f.file("b.go", wc.Counts{Lines: 10, Bytes: 2000}, nil)
+	if err := f.end(wc.Counts{Lines: 40, Bytes: 3000}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "{{lines}}\tpath\\n\n" +
+		" 30\ta.go |75.0%\n" +
+		" 10\tb.go |25.0%\n" +
+		" 40\ttotal 3.0K\n"
+
+	if got := buf.String(); got != want {
+		t.Errorf("output:\nActual:\n%q\nExpected:\n%q", got, want)
+	}
+
+	if _, err := newTemplateFormatter("{{.Lines", "", "", &buf); err == nil {
+		t.Error("an unclosed action did not fail")
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	tests := []struct {
+		n    int64
+		base int64
+		want string
+	}{
+		{0, 1024, "0"},
+		{1023, 1024, "1023"},
+		{1024, 1024, "1.0K"},
+		{1228, 1024, "1.2K"},
+		{1229, 1024, "1.3K"},
+		{10239, 1024, "10K"},
+		{1048575, 1024, "1.0M"},
+		{3565158, 1024, "3.4M"},
+		{1000, 1000, "1.0K"},
+		{999999, 1000, "1.0M"},
+		{1 << 62, 1024, "4.0E"},
+	}
+
+	for _, test := range tests {
+		if got := humanSize(test.n, test.base); got != test.want {
+			t.Errorf("humanSize(%d, %d): Actual:%q Expected:%q", test.n, test.base, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// symlinkPolicy selects the symbolic links followed when walking directories
+type symlinkPolicy int
+
+const (
+	// followNone never follows symbolic links, like -P
+	followNone symlinkPolicy = iota
+
+	// followCommandLine only follows the symbolic links given on the command
+	// line, like -H
+	followCommandLine
+
+	// followAll follows every symbolic link, like --dereference
+	followAll
+)
+
+// errLoop is reported for a symbolic link to a directory on the path that
+// leads to the link, which would otherwise be walked forever
+var errLoop = errors.New("file system loop detected")
+
+// walker lists the files to count for the names given on the command line,
+// and the regular files found under the directories among them
+type walker struct {
+	// recursive walks the directories given on the command line
+	recursive bool
+
+	// maxDepth is the number of levels of subdirectories descended below a
+	// directory given on the command line, whose own files are always
+	// counted; a negative depth has no limit
+	maxDepth int
+
+	symlinks symlinkPolicy
+
+	// oneFileSystem skips the directories on another file system than the
+	// directory given on the command line they are found under
+	oneFileSystem bool
+
+	// include, when not empty, and exclude select the files counted by
+	// their path below the directory given on the command line
+	include []globPattern
+	exclude []globPattern
+
+	// ignoreFiles skips the files ignored by the .gitignore and .ignore
+	// files found while walking, and .git directories
+	ignoreFiles bool
+
+	// ignores holds the ignore files that apply to each directory walked
+	ignores map[string]*ignoreList
+
+	// ancestors are the directories on the current descent path, indexed
+	// by their level below the directory given on the command line
+	ancestors []fs.FileInfo
+
+	// skipGenerated skips generated and binary files, and vendored
+	// directories while walking
+	skipGenerated bool
+
+	// report is called for the files and directories that cannot be walked
+	report func(path string, err error)
+
+	// skipped, when set, is called for the files and directories skipped
+	// as generated, binary or vendored, with the reason why
+	skipped func(path, reason string)
+}
+
+// topDir is a directory given on the command line
+type topDir struct {
+	path string
+	info fs.FileInfo
+}
+
+// expand returns the files to count for the given names. When recursive,
+// directories are replaced with the regular files found under them, in
+// lexical order, and symbolic links are skipped unless the policy follows
+// them. Other names, including "-", invalidName and names that do not
+// exist, are kept for the counter to count or report on, unless they are
+// generated or binary files to skip. It also reports whether any directory was walked.
+func (w *walker) expand(names []string) ([]string, bool) {
+	var (
+		files  []string
+		walked bool
+	)
+
+	for _, name := range names {
+		if name == "-" || name == invalidName {
+			files = append(files, name)
+			continue
+		}
+
+		stat := os.Stat
+		if w.symlinks == followNone {
+			stat = os.Lstat
+		}
+
+		info, err := stat(name)
+		if err == nil && info.Mode()&fs.ModeSymlink != 0 && w.recursive {
+			// like those found while walking, the symbolic links given on
+			// the command line are only followed with -H or --dereference
+			if w.skipped != nil {
+				w.skipped(name, "symbolic link, not followed without -H")
+			}
+
+			continue
+		}
+
+		if err == nil && info.IsDir() && w.recursive {
+			walked = true
+			files = w.walk(files, &topDir{path: name, info: info}, name, info, 0)
+			continue
+		}
+
+		if info, err := os.Stat(name); err == nil && info.Mode().IsRegular() && w.generated(name) {
+			continue
+		}
+
+		files = append(files, name)
+	}
+
+	return files, walked
+}
+
+// walk appends to files the regular files under the directory root, which is
+// depth levels below top, the directory given on the command line, and whose
+// info is given
+func (w *walker) walk(files []string, top *topDir, root string, info fs.FileInfo, depth int) []string {
+	if !w.within(depth) {
+		return files
+	}
+
+	w.ancestors = append(w.ancestors[:depth], info)
+
+	if w.ignoreFiles {
+		w.readIgnores(filepath.Clean(root))
+	}
+
+	// WalkDir does not descend into a symbolic link given as its root, unless
+	// a trailing separator resolves it to the directory it links to
+	start := root
+	if !os.IsPathSeparator(root[len(root)-1]) {
+		start += string(filepath.Separator)
+	}
+
+	filepath.WalkDir(start, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			w.report(path, err)
+			return nil
+		}
+
+		if path == start {
+			return nil
+		}
+
+		level := depth + levels(start, path)
+
+		switch {
+		case d.IsDir():
+			return w.enter(top, path, d, level)
+		case d.Type().IsRegular():
+			if !w.skip(top, path, false) && !w.generated(path) {
+				files = append(files, path)
+			}
+		case d.Type()&fs.ModeSymlink != 0 && w.symlinks == followAll:
+			files = w.follow(files, top, path, level)
+		}
+
+		return nil
+	})
+
+	return files
+}
+
+// enter tells WalkDir whether to descend into the directory at path, found
+// level levels below top
+func (w *walker) enter(top *topDir, path string, d fs.DirEntry, level int) error {
+	if !w.within(level) || w.skip(top, path, true) || w.vendored(path) {
+		return filepath.SkipDir
+	}
+
+	info, err := d.Info()
+	if err != nil {
+		w.report(path, err)
+		return filepath.SkipDir
+	}
+
+	if w.oneFileSystem && !sameDevice(top.info, info) {
+		return filepath.SkipDir
+	}
+
+	w.ancestors = append(w.ancestors[:level], info)
+
+	if w.ignoreFiles {
+		w.readIgnores(path)
+	}
+
+	return nil
+}
+
+// follow appends to files the regular file, or the regular files under the
+// directory, that the symbolic link at path leads to
+func (w *walker) follow(files []string, top *topDir, path string, level int) []string {
+	info, err := os.Stat(path)
+	if err != nil {
+		w.report(path, err)
+		return files
+	}
+
+	switch {
+	case w.skip(top, path, info.IsDir()):
+		return files
+	case info.Mode().IsRegular():
+		if w.generated(path) {
+			return files
+		}
+
+		return append(files, path)
+	case !info.IsDir(), w.vendored(path):
+		return files
+	case w.oneFileSystem && !sameDevice(top.info, info):
+		return files
+	}
+
+	// the directories on the path to the link are those on the levels above
+	for _, dir := range w.ancestors[:level] {
+		if sameFile(dir, info) {
+			w.report(path, errLoop)
+			return files
+		}
+	}
+
+	return w.walk(files, top, path, info, level)
+}
+
+// skip reports whether the file or directory at path is left out by the
+// include, exclude and ignore rules. Directories are only matched against the
+// exclude and ignore rules, so that the files under them can be included.
+func (w *walker) skip(top *topDir, path string, isDir bool) bool {
+	rel, err := filepath.Rel(top.path, path)
+	if err != nil {
+		return false
+	}
+
+	rel = filepath.ToSlash(rel)
+
+	switch {
+	case !isDir && len(w.include) > 0 && !matchAny(w.include, rel, false):
+		return true
+	case matchAny(w.exclude, rel, isDir):
+		return true
+	case !w.ignoreFiles:
+		return false
+	case isDir && filepath.Base(path) == ".git":
+		return true
+	}
+
+	return w.ignores[filepath.Dir(path)].ignored(path, isDir)
+}
+
+// generated reports whether the regular file at path is skipped as a
+// generated or binary file
+func (w *walker) generated(path string) bool {
+	if !w.skipGenerated {
+		return false
+	}
+
+	reason := skipReason(path)
+	if reason == "" {
+		return false
+	}
+
+	if w.skipped != nil {
+		w.skipped(path, reason)
+	}
+
+	return true
+}
+
+// vendored reports whether the directory at path is skipped as a directory
+// of third-party code
+func (w *walker) vendored(path string) bool {
+	if !w.skipGenerated || !vendoredDirs[filepath.Base(path)] {
+		return false
+	}
+
+	if w.skipped != nil {
+		w.skipped(path, "vendored directory")
+	}
+
+	return true
+}
+
+// readIgnores reads the ignore files of the directory at path, once the
+// directory above it has been read
+func (w *walker) readIgnores(path string) {
+	if w.ignores == nil {
+		w.ignores = make(map[string]*ignoreList)
+	}
+
+	list, err := readIgnoreList(path, w.ignores[filepath.Dir(path)])
+	if err != nil {
+		w.report(path, err)
+	}
+
+	w.ignores[path] = list
+}
+
+// within reports whether a directory level levels below the one given on the
+// command line is descended, so that the files directly in it are counted
+func (w *walker) within(level int) bool {
+	return w.maxDepth < 0 || level <= w.maxDepth
+}
+
+// levels returns how many levels of directories path is below root
+func levels(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return 1
+	}
+
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
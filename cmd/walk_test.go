@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWalkerExpand(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"a/1", "a/b/2", "a/b/c/3", "other/4", "lp/a/g", "lp/b/g"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte("x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	links := map[string]string{
+		"a/b/other": "../../other",
+		"a/b/c/up":  "..",
+		"lp/a/l":    "../b",
+		"lp/b/l":    "../a",
+		"la":        "a",
+		"lf":        "a/1",
+	}
+
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(dir, link)); err != nil {
+			t.Skip("symbolic links are not supported:", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		root    string
+		walker  walker
+		want    []string
+		errors  []string
+		skipped []string
+	}{
+		{"no limit", "a", walker{recursive: true, maxDepth: -1}, []string{"a/1", "a/b/2", "a/b/c/3"}, nil, nil},
+		{"max depth", "a", walker{recursive: true, maxDepth: 1}, []string{"a/1", "a/b/2"}, nil, nil},
+		{"no descent", "a", walker{recursive: true, maxDepth: 0}, []string{"a/1"}, nil, nil},
+		{
+			"following links",
+			"a",
+			walker{recursive: true, maxDepth: -1, symlinks: followAll},
+			[]string{"a/1", "a/b/2", "a/b/c/3", "a/b/other/4"},
+			[]string{"a/b/c/up"},
+			nil,
+		},
+		{
+			"loop through two directories",
+			"lp",
+			walker{recursive: true, maxDepth: -1, symlinks: followAll},
+			[]string{"lp/a/g", "lp/a/l/g", "lp/b/g", "lp/b/l/g"},
+			[]string{"lp/a/l/l", "lp/b/l/l"},
+			nil,
+		},
+		{"directory link with -P", "la", walker{recursive: true, maxDepth: -1}, nil, nil, []string{"la"}},
+		{"file link with -P", "lf", walker{recursive: true, maxDepth: -1}, nil, nil, []string{"lf"}},
+		{
+			"directory link with -H",
+			"la",
+			walker{recursive: true, maxDepth: -1, symlinks: followCommandLine},
+			[]string{"la/1", "la/b/2", "la/b/c/3"},
+			nil,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var errors, skipped []string
+
+			w := test.walker
+			w.report = func(path string, err error) {
+				errors = append(errors, relative(t, dir, path))
+			}
+			w.skipped = func(path, reason string) {
+				skipped = append(skipped, relative(t, dir, path))
+			}
+
+			files, walked := w.expand([]string{filepath.Join(dir, test.root)})
+			if walked != (test.want != nil) {
+				t.Errorf("walked: Actual:%v Expected:%v", walked, test.want != nil)
+			}
+
+			var got []string
+			for _, file := range files {
+				got = append(got, relative(t, dir, file))
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("files: Actual:%q Expected:%q", got, test.want)
+			}
+
+			if !reflect.DeepEqual(errors, test.errors) {
+				t.Errorf("errors: Actual:%q Expected:%q", errors, test.errors)
+			}
+
+			if !reflect.DeepEqual(skipped, test.skipped) {
+				t.Errorf("skipped: Actual:%q Expected:%q", skipped, test.skipped)
+			}
+		})
+	}
+}
+
+func relative(t *testing.T, dir, path string) string {
+	t.Helper()
+
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,103 @@
+package wc
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// asciiFastPath enables countASCII; benchmarks turn it off to compare with
+// the per-character loop
+var asciiFastPath = true
+
+const (
+	// lowBits and highBits have the low and the high bit set in each byte
+	// of a word, and asciiBits all the other bits
+	lowBits   = 0x0101010101010101
+	highBits  = 0x8080808080808080
+	asciiBits = 0x7f7f7f7f7f7f7f7f
+)
+
+// countASCII counts the leading 8-byte words of p that are plain ASCII
+// text, eight bytes at a time, and returns the number of bytes it counted.
+// It stops at the first word holding a byte that is not ASCII, a control
+// character other than white space, or, when line widths are counted, a
+// tab, newline or other white space control character, which the caller
+// counts one character at a time.
+func (c *counter) countASCII(p []byte) int {
+	if !asciiFastPath {
+		return 0
+	}
+
+	n := 0
+
+	for ; len(p)-n >= 8; n += 8 {
+		w := binary.LittleEndian.Uint64(p[n:])
+		if w&highBits != 0 {
+			break
+		}
+
+		// white space controls are \t, \n, \v, \f and \r; the other bytes
+		// below the space, and DEL, neither start nor end a word
+		controls := inRange(w, '\t', '\r')
+		spaces := controls | equal(w, ' ')
+
+		if (less(w, ' ')|equal(w, 0x7f))&^controls != 0 {
+			break
+		}
+
+		if controls != 0 {
+			if c.Options.MaxLineLength {
+				break
+			}
+
+			if newlines := equal(w, '\n'); newlines != 0 {
+				c.Counts.Lines += int64(bits.OnesCount64(newlines))
+				c.LineBreak = true
+			}
+		} else {
+			c.line.add(8)
+		}
+
+		// a word starts at each printable byte that follows a space, or
+		// that comes first when the previous character is not in a word
+		printable := highBits &^ spaces
+		previous := spaces << 8
+		if !c.inWord {
+			previous |= 0x80
+		}
+
+		c.Counts.Words += int64(bits.OnesCount64(printable & previous))
+		c.inWord = printable>>63 != 0
+
+		if !c.WordEdge {
+			c.WordEdge = true
+			c.WordStart = printable&0x80 != 0
+		}
+
+		c.OpenLine = p[n+7] != '\n'
+	}
+
+	if n > 0 {
+		c.headDone = true
+		c.Counts.Bytes += int64(n)
+		c.Counts.Chars += int64(n)
+	}
+
+	return n
+}
+
+// equal sets the high bit of each byte of w, an ASCII word, equal to b
+func equal(w uint64, b byte) uint64 {
+	x := w ^ (lowBits * uint64(b))
+	return ^((x + asciiBits) | x) & highBits
+}
+
+// less sets the high bit of each byte of w, an ASCII word, below b
+func less(w uint64, b byte) uint64 {
+	return ^(w + lowBits*uint64(0x80-b)) & highBits
+}
+
+// inRange sets the high bit of each byte of w, an ASCII word, from lo to hi
+func inRange(w uint64, lo, hi byte) uint64 {
+	return (w + lowBits*uint64(0x80-lo)) &^ (w + lowBits*uint64(0x7f-hi)) & highBits
+}
@@ -0,0 +1,58 @@
+package wc
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"testing"
+)
+
+func TestCountASCII(t *testing.T) {
+	alphabet := []byte("ab  \t\n\r\f\v\x01\x7f~!é")
+	rng := rand.New(rand.NewSource(1))
+
+	for _, opts := range []Options{{}, {Lines: true, Words: true}, {Charset: CharsetBytes}} {
+		for n := 0; n < 2000; n++ {
+			b := make([]byte, rng.Intn(64))
+			for i := range b {
+				b[i] = alphabet[rng.Intn(len(alphabet))]
+			}
+
+			expected := countWithoutFastPath(b, opts)
+			if actual := CountString(string(b), opts); actual != expected {
+				t.Fatalf("%q: Actual:%+v Expected:%+v", b, actual, expected)
+			}
+		}
+	}
+}
+
+func countWithoutFastPath(b []byte, opts Options) Counts {
+	asciiFastPath = false
+	defer func() { asciiFastPath = true }()
+
+	return CountString(string(b), opts)
+}
+
+func BenchmarkCount(b *testing.B) {
+	data, err := ioutil.ReadFile("../sample-2mb-text-file.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, fastPath := range []bool{true, false} {
+		name := "per-character"
+		if fastPath {
+			name = "word-at-a-time"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			asciiFastPath = fastPath
+			defer func() { asciiFastPath = true }()
+
+			b.SetBytes(int64(len(data)))
+
+			for i := 0; i < b.N; i++ {
+				CountPartialBytes(data, Options{Lines: true, Words: true, Bytes: true}).Total()
+			}
+		})
+	}
+}
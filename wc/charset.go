@@ -0,0 +1,89 @@
+package wc
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Charset selects how the bytes of an input are split into characters
+type Charset int
+
+const (
+	// CharsetUTF8 decodes the input as UTF-8; invalid sequences are not
+	// counted as characters
+	CharsetUTF8 Charset = iota
+
+	// CharsetBytes treats every byte as a character, like the C and POSIX
+	// locales do
+	CharsetBytes
+)
+
+// LocaleCharset returns the charset of the named locale, such as
+// "en_US.UTF-8" or "C". Locales whose codeset is UTF-8 decode characters, any
+// other locale, including C and POSIX, counts bytes. An empty name is the C
+// locale, which programs run in when LC_ALL, LC_CTYPE and LANG are all unset.
+func LocaleCharset(name string) Charset {
+	codeset := name
+	if i := strings.IndexByte(codeset, '.'); i >= 0 {
+		codeset = codeset[i+1:]
+	}
+
+	if i := strings.IndexByte(codeset, '@'); i >= 0 {
+		codeset = codeset[:i]
+	}
+
+	switch strings.ToLower(codeset) {
+	case "utf-8", "utf8":
+		return CharsetUTF8
+	}
+
+	return CharsetBytes
+}
+
+// isSpace reports whether r separates words. Only the ASCII white space
+// characters do so in the C locale; in UTF-8 the Unicode white space
+// characters do too, and so do the non-breaking spaces and the word joiner,
+// as in GNU wc, unless posix asks for the POSIX white space only.
+func (cs Charset) isSpace(r rune, posix bool) bool {
+	switch r {
+	case ' ', '\t', '\n', '\v', '\f', '\r':
+		return true
+	}
+
+	if cs == CharsetBytes || r < utf8.RuneSelf {
+		return false
+	}
+
+	switch r {
+	case 0xa0, 0x2007, 0x202f, 0x2060:
+		return !posix
+	}
+
+	return unicode.IsSpace(r)
+}
+
+// isPrint reports whether r is a printable character. Only printable
+// characters start a word, while other non-space characters, such as control
+// characters and invalid bytes, neither start nor end one, as in GNU wc.
+func (cs Charset) isPrint(r rune) bool {
+	if cs == CharsetBytes || r < utf8.RuneSelf {
+		return r >= ' ' && r < 0x7f
+	}
+
+	return unicode.IsGraphic(r) || unicode.In(r, unicode.Cf, unicode.Co)
+}
+
+// width returns the number of columns r takes; in the C locale only the
+// printable ASCII characters take any
+func (cs Charset) width(r rune) int {
+	if cs == CharsetBytes {
+		if cs.isPrint(r) {
+			return 1
+		}
+
+		return 0
+	}
+
+	return runeWidth(r)
+}
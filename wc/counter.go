@@ -0,0 +1,309 @@
+package wc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unicode/utf8"
+)
+
+// bufferSize is the size of the chunks read from an input while counting
+const bufferSize = 32 * 1024
+
+// counter computes every metric of a chunk in a single pass. It only keeps
+// the state needed to carry words, lines and multibyte characters across
+// buffer boundaries, so memory use does not depend on the input size or on
+// the length of its lines.
+type counter struct {
+	Partial
+
+	// inWord reports whether the last character read is part of a word
+	inWord bool
+
+	// line is the width of the current line since its last line break
+	line LineWidth
+
+	// headDone reports whether a byte that is not a continuation byte, or
+	// the longest possible run of them, has been read
+	headDone bool
+}
+
+// newCounter returns a counter computing the counts selected by want
+func newCounter(want Options) *counter {
+	return &counter{Partial: Partial{Options: want}}
+}
+
+// Write counts the bytes of p; a character split at the end of p is kept
+// until the next write completes it. It never returns an error.
+func (c *counter) Write(p []byte) (int, error) {
+	n := len(p)
+
+	if !c.decodes() {
+		c.countBytes(p)
+		return n, nil
+	}
+
+	if c.Options.Charset == CharsetBytes {
+		for i := 0; i < len(p); i++ {
+			if k := c.countASCII(p[i:]); k > 0 {
+				i += k - 1
+				continue
+			}
+
+			c.rune(rune(p[i]), 1)
+		}
+
+		return n, nil
+	}
+
+	if !c.headDone {
+		p = c.readHead(p)
+	}
+
+	if len(c.Tail) > 0 {
+		p = c.completeTail(p)
+		if len(c.Tail) > 0 {
+			return n, nil
+		}
+	}
+
+	if !c.Options.Words && !c.Options.MaxLineLength {
+		c.countChars(p)
+		return n, nil
+	}
+
+	for i := 0; i < len(p); {
+		if p[i] < utf8.RuneSelf {
+			if k := c.countASCII(p[i:]); k > 0 {
+				i += k
+				continue
+			}
+
+			c.rune(rune(p[i]), 1)
+			i++
+			continue
+		}
+
+		if !utf8.FullRune(p[i:]) {
+			c.Tail = append(c.Tail[:0], p[i:]...)
+			c.OpenLine = true
+			break
+		}
+
+		r, size := utf8.DecodeRune(p[i:])
+		c.rune(r, size)
+		i += size
+	}
+
+	return n, nil
+}
+
+// decodes reports whether the selected counts need the input decoded into
+// characters; bytes and newlines, and characters in the C locale, can be
+// counted on the raw bytes
+func (c *counter) decodes() bool {
+	if c.Options.Charset == CharsetBytes {
+		return c.Options.Words || c.Options.MaxLineLength
+	}
+
+	return c.Options.Chars || c.Options.Words || c.Options.MaxLineLength
+}
+
+// countBytes counts bytes, characters and newlines without decoding
+// characters
+func (c *counter) countBytes(p []byte) {
+	if len(p) == 0 {
+		return
+	}
+
+	c.Counts.Bytes += int64(len(p))
+	c.Counts.Chars += int64(len(p))
+
+	if c.Options.Lines {
+		c.Counts.Lines += int64(bytes.Count(p, []byte{'\n'}))
+		c.OpenLine = p[len(p)-1] != '\n'
+	}
+}
+
+// countChars counts the characters, bytes and newlines of p, decoding
+// characters without looking at them, for when neither words nor line
+// widths are selected. A character cut short at the end of p is kept until
+// the next write completes it.
+func (c *counter) countChars(p []byte) {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-(utf8.UTFMax-1); i-- {
+		if utf8.RuneStart(p[i]) {
+			if !utf8.FullRune(p[i:]) {
+				c.Tail = append(c.Tail[:0], p[i:]...)
+				p = p[:i]
+			}
+
+			break
+		}
+	}
+
+	c.Counts.Bytes += int64(len(p))
+
+	if c.Options.Lines {
+		c.Counts.Lines += int64(bytes.Count(p, []byte{'\n'}))
+	}
+
+	if len(c.Tail) > 0 {
+		c.OpenLine = true
+	} else if len(p) > 0 {
+		c.OpenLine = p[len(p)-1] != '\n'
+	}
+
+	for i := 0; i < len(p); {
+		if len(p)-i >= 8 && binary.LittleEndian.Uint64(p[i:])&highBits == 0 {
+			c.Counts.Chars += 8
+			i += 8
+			continue
+		}
+
+		if p[i] < utf8.RuneSelf {
+			c.Counts.Chars++
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRune(p[i:])
+		if r != utf8.RuneError || size > 1 {
+			c.Counts.Chars++
+		}
+
+		i += size
+	}
+}
+
+// readHead moves the continuation bytes p starts with into the head of the
+// chunk and returns the rest of p
+func (c *counter) readHead(p []byte) []byte {
+	for len(p) > 0 {
+		if utf8.RuneStart(p[0]) || len(c.Head) == utf8.UTFMax-1 {
+			c.headDone = true
+			break
+		}
+
+		c.Head = append(c.Head, p[0])
+		p = p[1:]
+	}
+
+	return p
+}
+
+// completeTail decodes the character left incomplete by the previous write
+// using the first bytes of p and returns the rest of p
+func (c *counter) completeTail(p []byte) []byte {
+	var tmp [2 * utf8.UTFMax]byte
+
+	k := copy(tmp[:], c.Tail)
+	m := copy(tmp[k:], p)
+	buf := tmp[:k+m]
+
+	i := 0
+	for i < k {
+		if !utf8.FullRune(buf[i:]) {
+			c.Tail = append(c.Tail[:0], buf[i:]...)
+			return nil
+		}
+
+		r, size := utf8.DecodeRune(buf[i:])
+		c.rune(r, size)
+		i += size
+	}
+
+	c.Tail = c.Tail[:0]
+
+	return p[i-k:]
+}
+
+// rune counts a single decoded character that is size bytes long; an
+// invalid byte is counted as a byte but not as a character
+func (c *counter) rune(r rune, size int) {
+	c.headDone = true
+	c.Counts.Bytes += int64(size)
+
+	invalid := r == utf8.RuneError && size == 1 && c.Options.Charset == CharsetUTF8
+	if !invalid {
+		c.Counts.Chars++
+	}
+
+	if r == '\n' {
+		c.Counts.Lines++
+		c.OpenLine = false
+		c.endLine()
+	} else {
+		c.OpenLine = true
+		if !invalid && c.Options.MaxLineLength {
+			c.advance(r)
+		}
+	}
+
+	if !c.Options.Words || invalid {
+		return
+	}
+
+	switch {
+	case c.Options.Charset.isSpace(r, c.Options.POSIXSpaces):
+		c.wordEdge(false)
+		c.inWord = false
+	case c.Options.Charset.isPrint(r):
+		c.wordEdge(true)
+		if !c.inWord {
+			c.inWord = true
+			c.Counts.Words++
+		}
+	}
+}
+
+// wordEdge records whether the first character of the chunk that starts or
+// ends a word starts one
+func (c *counter) wordEdge(start bool) {
+	if !c.WordEdge {
+		c.WordEdge = true
+		c.WordStart = start
+	}
+}
+
+// advance moves the display position of the current line past r. Tabs
+// advance to the next tab stop, while carriage returns and form feeds go back
+// to the start of the line.
+func (c *counter) advance(r rune) {
+	switch r {
+	case '\r', '\f':
+		c.endLine()
+	case '\t':
+		c.line.tab()
+	default:
+		c.line.add(int64(c.Options.Charset.width(r)))
+	}
+}
+
+// endLine records the width of the current line and goes back to its start.
+// The width of the first line of the chunk depends on the column the chunk
+// starts at, so it is kept aside until the chunk is merged.
+func (c *counter) endLine() {
+	if !c.LineBreak {
+		c.LineBreak = true
+		c.FirstLine = c.line
+	} else if width := c.line.From(0); width > c.Counts.MaxLineLength {
+		c.Counts.MaxLineLength = width
+	}
+
+	c.line = LineWidth{}
+}
+
+// partial returns the counts of the chunk read so far
+func (c *counter) partial() Partial {
+	p := c.Partial
+
+	p.WordEnd = c.inWord
+	p.LastLine = c.line
+	if !p.LineBreak {
+		p.FirstLine = c.line
+	}
+
+	p.Head = append([]byte(nil), p.Head...)
+	p.Tail = append([]byte(nil), p.Tail...)
+
+	return p
+}
@@ -0,0 +1,171 @@
+//go:build ignore
+// +build ignore
+
+// gen_width_table generates width_table.go from the Unicode Character
+// Database file EastAsianWidth.txt.
+//
+// Usage:
+//
+//	go run gen_width_table.go [-input EastAsianWidth.txt] [-version 14.0.0]
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	input   = flag.String("input", "", "path of EastAsianWidth.txt; downloaded from unicode.org when empty")
+	version = flag.String("version", "14.0.0", "Unicode version of the input")
+	output  = flag.String("output", "width_table.go", "path of the generated file")
+)
+
+type runeRange struct {
+	lo, hi rune
+}
+
+func main() {
+	flag.Parse()
+
+	data, err := openInput()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer data.Close()
+
+	ranges, err := parseWide(data)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := format.Source(render(ranges))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func openInput() (io.ReadCloser, error) {
+	if *input != "" {
+		return os.Open(*input)
+	}
+
+	url := "https://www.unicode.org/Public/" + *version + "/ucd/EastAsianWidth.txt"
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+// parseWide returns the merged ranges of characters whose East Asian Width
+// property is W (wide) or F (fullwidth)
+func parseWide(r io.Reader) ([]runeRange, error) {
+	var ranges []runeRange
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Split(line, ";")
+		if len(fields) != 2 {
+			continue
+		}
+
+		property := strings.TrimSpace(fields[1])
+		if property != "W" && property != "F" {
+			continue
+		}
+
+		lo, hi, err := parseRange(strings.TrimSpace(fields[0]))
+		if err != nil {
+			return nil, err
+		}
+
+		if n := len(ranges); n > 0 && ranges[n-1].hi+1 == lo {
+			ranges[n-1].hi = hi
+		} else {
+			ranges = append(ranges, runeRange{lo, hi})
+		}
+	}
+
+	return ranges, scanner.Err()
+}
+
+func parseRange(s string) (rune, rune, error) {
+	parts := strings.SplitN(s, "..", 2)
+
+	lo, err := strconv.ParseUint(parts[0], 16, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	hi := lo
+	if len(parts) == 2 {
+		if hi, err = strconv.ParseUint(parts[1], 16, 32); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return rune(lo), rune(hi), nil
+}
+
+func render(ranges []runeRange) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "// Code generated by gen_width_table.go from EastAsianWidth.txt (Unicode %s); DO NOT EDIT.\n\n", *version)
+	fmt.Fprintf(&b, "package wc\n\nimport \"unicode\"\n\n")
+	fmt.Fprintf(&b, "// wideTable holds the characters whose East Asian Width is W or F\n")
+	fmt.Fprintf(&b, "var wideTable = &unicode.RangeTable{\n")
+
+	var r16, r32 []runeRange
+	for _, r := range ranges {
+		switch {
+		case r.hi <= 0xFFFF:
+			r16 = append(r16, r)
+		case r.lo > 0xFFFF:
+			r32 = append(r32, r)
+		default:
+			r16 = append(r16, runeRange{r.lo, 0xFFFF})
+			r32 = append(r32, runeRange{0x10000, r.hi})
+		}
+	}
+
+	fmt.Fprintf(&b, "R16: []unicode.Range16{\n")
+	for _, r := range r16 {
+		fmt.Fprintf(&b, "{0x%04x, 0x%04x, 1},\n", r.lo, r.hi)
+	}
+	fmt.Fprintf(&b, "},\n")
+
+	fmt.Fprintf(&b, "R32: []unicode.Range32{\n")
+	for _, r := range r32 {
+		fmt.Fprintf(&b, "{0x%05x, 0x%05x, 1},\n", r.lo, r.hi)
+	}
+	fmt.Fprintf(&b, "},\n")
+
+	fmt.Fprintf(&b, "}\n")
+
+	return []byte(b.String())
+}
@@ -0,0 +1,93 @@
+package wc
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// CountAt computes the metrics of the first size bytes of r selected by
+// opts. The bytes are split into jobs ranges of about the same length, each
+// read with ReadAt and counted on its own goroutine; the partial counts are
+// then merged in order, so the result is the same as counting r in a single
+// pass. The first error stops the other goroutines and is returned.
+func CountAt(ctx context.Context, r io.ReaderAt, size int64, opts Options, jobs int) (Counts, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		ranges   = SplitRanges(size, jobs)
+		partials = make([]Partial, len(ranges))
+	)
+
+	for i, rg := range ranges {
+		wg.Add(1)
+		go func(i int, rg Range) {
+			defer wg.Done()
+
+			p, err := CountPartial(ctx, io.NewSectionReader(r, rg.Off, rg.Len), opts)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+
+			partials[i] = p
+		}(i, rg)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return Counts{}, firstErr
+	}
+
+	whole := partials[0]
+	for _, p := range partials[1:] {
+		whole = whole.Merge(p)
+	}
+
+	return whole.Total(), nil
+}
+
+// Range is the Len bytes of an input starting at offset Off
+type Range struct {
+	Off, Len int64
+}
+
+// SplitRanges splits the first size bytes of an input into at most jobs
+// consecutive ranges of about the same length, to be counted concurrently
+// and merged in order. Ranges are never empty, except for the single range
+// of an empty input, and never shorter than a read buffer but for the last
+// one, as smaller ranges cost more to start than they save.
+func SplitRanges(size int64, jobs int) []Range {
+	if size <= 0 {
+		return []Range{{}}
+	}
+
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	if int64(jobs) > size/bufferSize {
+		jobs = int(size/bufferSize) + 1
+	}
+
+	chunk := (size + int64(jobs) - 1) / int64(jobs)
+	ranges := make([]Range, 0, jobs)
+
+	for off := int64(0); off < size; off += chunk {
+		n := chunk
+		if off+n > size {
+			n = size - off
+		}
+
+		ranges = append(ranges, Range{Off: off, Len: n})
+	}
+
+	return ranges
+}
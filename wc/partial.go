@@ -0,0 +1,374 @@
+package wc
+
+import (
+	"context"
+	"io"
+	"unicode/utf8"
+)
+
+// Partial holds the counts of a chunk of a larger stream, together with the
+// state at the edges of the chunk needed to combine it with its neighbours.
+// Chunks counted on their own and merged in stream order with Merge give the
+// same counts as the whole stream, even when words, lines or multibyte
+// characters straddle the chunk boundaries.
+//
+// The zero Partial is an empty chunk; merging it with p returns p.
+type Partial struct {
+	// Options are the options the chunk was counted with
+	Options Options
+
+	// Counts holds the counts of the chunk, leaving out the bytes held in
+	// Head and Tail. Words are counted as if the chunk started outside a
+	// word, MaxLineLength only covers the lines that start and end inside
+	// the chunk, and Lines only counts newline characters.
+	Counts Counts
+
+	// Head holds the continuation bytes the chunk starts with, which may
+	// complete a character started at the end of the previous chunk
+	Head []byte
+
+	// Tail holds the bytes of an incomplete character the chunk ends with
+	Tail []byte
+
+	// WordEdge reports whether the chunk has a character that starts or
+	// ends a word; control characters and invalid bytes do neither
+	WordEdge bool
+
+	// WordStart reports whether the first such character starts a word,
+	// which continues the word the previous chunk may end in
+	WordStart bool
+
+	// WordEnd reports whether the chunk ends in the middle of a word
+	WordEnd bool
+
+	// LineBreak reports whether the chunk has a newline, carriage return or
+	// form feed, each of which ends the width of a line
+	LineBreak bool
+
+	// FirstLine is the width of the chunk up to its first line break, or of
+	// the whole chunk when it has none
+	FirstLine LineWidth
+
+	// LastLine is the width of the chunk after its last line break, or of
+	// the whole chunk when it has none
+	LastLine LineWidth
+
+	// OpenLine reports whether bytes follow the last newline of the chunk
+	OpenLine bool
+}
+
+// LineWidth is the display width of a run of characters without line
+// breaks. Because tabs advance to the next tab stop, the column a run ends at
+// depends on the column it starts at: Before is the width up to the first
+// tab and After the width from the tab stop that tab reaches.
+type LineWidth struct {
+	Tab    bool
+	Before int64
+	After  int64
+}
+
+// From returns the column the run ends at when it starts at column pos
+func (w LineWidth) From(pos int64) int64 {
+	if !w.Tab {
+		return pos + w.Before
+	}
+
+	return nextTabStop(pos+w.Before) + w.After
+}
+
+// Then returns the width of the run w followed by the run next
+func (w LineWidth) Then(next LineWidth) LineWidth {
+	switch {
+	case !next.Tab:
+		if w.Tab {
+			w.After += next.Before
+		} else {
+			w.Before += next.Before
+		}
+
+		return w
+	case !w.Tab:
+		next.Before += w.Before
+		return next
+	}
+
+	w.After = nextTabStop(w.After+next.Before) + next.After
+
+	return w
+}
+
+// add advances the run by width columns
+func (w *LineWidth) add(width int64) {
+	if w.Tab {
+		w.After += width
+	} else {
+		w.Before += width
+	}
+}
+
+// tab advances the run to the next tab stop
+func (w *LineWidth) tab() {
+	if w.Tab {
+		w.After = nextTabStop(w.After)
+	} else {
+		w.Tab = true
+	}
+}
+
+// nextTabStop returns the first tab stop after column pos
+func nextTabStop(pos int64) int64 {
+	return pos + tabWidth - pos%tabWidth
+}
+
+// CountPartial counts r as a chunk of a larger stream, reading it in
+// fixed-size buffers until EOF. It stops early with the context's error when
+// ctx is done.
+func CountPartial(ctx context.Context, r io.Reader, opts Options) (Partial, error) {
+	if !opts.Any() {
+		opts = opts.All()
+	}
+
+	c := newCounter(opts)
+
+	buf := make([]byte, bufferSize)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return Partial{}, err
+		}
+
+		n, err := r.Read(buf)
+		c.Write(buf[:n])
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return Partial{}, err
+		}
+	}
+
+	return c.partial(), nil
+}
+
+// CountPartialBytes counts b as a chunk of a larger stream
+func CountPartialBytes(b []byte, opts Options) Partial {
+	if !opts.Any() {
+		opts = opts.All()
+	}
+
+	c := newCounter(opts)
+
+	c.Write(b)
+
+	return c.partial()
+}
+
+// size returns the number of bytes in the chunk
+func (p Partial) size() int64 {
+	return p.Counts.Bytes + int64(len(p.Head)+len(p.Tail))
+}
+
+// openLine reports whether the chunk ends inside a line, that is whether any
+// byte follows its last newline
+func (p Partial) openLine() bool {
+	if p.Counts.Lines > 0 {
+		return p.OpenLine
+	}
+
+	return p.size() > 0
+}
+
+// Merge returns the counts of the chunk p followed by the chunk q. Merge is
+// associative, so chunks can be merged in any grouping as long as their order
+// is kept.
+func (p Partial) Merge(q Partial) Partial {
+	switch {
+	case q.size() == 0:
+		return p
+	case p.size() == 0:
+		return q
+	}
+
+	if p.Counts.Bytes == 0 && len(p.Tail) == 0 {
+		// p only holds continuation bytes, which the head of q extends
+		return p.mergeHead(q)
+	}
+
+	head := q.Head
+	q.Head = nil
+
+	if len(p.Tail) == 0 {
+		// nothing before the head of q can start a character
+		return p.join(invalidBytes(p.Options, len(head))).join(q)
+	}
+
+	split := append(append([]byte(nil), p.Tail...), head...)
+	p.Tail = nil
+
+	if !utf8.FullRune(split) && q.size() == 0 {
+		// the character is still incomplete, later chunks may complete it
+		p.Tail = split
+		return p
+	}
+
+	return p.join(decodeBytes(p.Options, split)).join(q)
+}
+
+// mergeHead merges the chunk p, made only of continuation bytes, with q.
+// The head of q extends the head of p up to the longest run of continuation
+// bytes a character can have; the bytes past it are invalid.
+func (p Partial) mergeHead(q Partial) Partial {
+	head := append([]byte(nil), p.Head...)
+	extra := q.Head
+
+	if room := utf8.UTFMax - 1 - len(head); room > 0 {
+		if room > len(extra) {
+			room = len(extra)
+		}
+
+		head = append(head, extra[:room]...)
+		extra = extra[room:]
+	}
+
+	q.Head = nil
+
+	r := invalidBytes(p.Options, len(extra)).join(q)
+	r.Head = head
+
+	return r
+}
+
+// join returns the counts of p followed by q, where no character straddles
+// the two: p has no Tail and q no Head
+func (p Partial) join(q Partial) Partial {
+	switch {
+	case q.size() == 0:
+		return p
+	case p.size() == 0:
+		return q
+	}
+
+	r := p
+	r.Tail = q.Tail
+
+	r.Counts.Bytes += q.Counts.Bytes
+	r.Counts.Lines += q.Counts.Lines
+	r.Counts.Chars += q.Counts.Chars
+	r.Counts.Words += q.Counts.Words
+
+	if p.WordEnd && q.WordStart {
+		// the word p ends with goes on in q
+		r.Counts.Words--
+	}
+
+	if q.WordEdge {
+		r.WordEnd = q.WordEnd
+		if !p.WordEdge {
+			r.WordEdge = true
+			r.WordStart = q.WordStart
+		}
+	}
+
+	switch {
+	case p.LineBreak && q.LineBreak:
+		r.LastLine = q.LastLine
+		r.Counts.MaxLineLength = max64(p.Counts.MaxLineLength, q.Counts.MaxLineLength)
+		r.Counts.MaxLineLength = max64(r.Counts.MaxLineLength, p.LastLine.Then(q.FirstLine).From(0))
+	case p.LineBreak:
+		r.LastLine = p.LastLine.Then(q.FirstLine)
+	case q.LineBreak:
+		r.LineBreak = true
+		r.FirstLine = p.FirstLine.Then(q.FirstLine)
+		r.LastLine = q.LastLine
+		r.Counts.MaxLineLength = q.Counts.MaxLineLength
+	default:
+		r.FirstLine = p.FirstLine.Then(q.FirstLine)
+		r.LastLine = r.FirstLine
+	}
+
+	r.OpenLine = q.openLine() || (q.Counts.Lines == 0 && p.openLine())
+
+	return r
+}
+
+// Total returns the counts of p as a whole stream: the bytes of an
+// incomplete character at either edge are invalid, the first and last lines
+// are measured from the first column, and a final unterminated line is
+// counted when logical lines are selected. Counts that were not selected are
+// zero.
+func (p Partial) Total() Counts {
+	head, tail := len(p.Head), len(p.Tail)
+	p.Head, p.Tail = nil, nil
+
+	whole := invalidBytes(p.Options, head).join(p).join(invalidBytes(p.Options, tail))
+
+	result := whole.Counts
+	result.MaxLineLength = max64(result.MaxLineLength, whole.FirstLine.From(0))
+	result.MaxLineLength = max64(result.MaxLineLength, whole.LastLine.From(0))
+
+	if whole.Options.LogicalLines && whole.openLine() {
+		result.Lines++
+	}
+
+	return whole.Options.mask(result)
+}
+
+// mask zeroes the counts of c that are not selected by opts
+func (opts Options) mask(c Counts) Counts {
+	if !opts.Lines {
+		c.Lines = 0
+	}
+
+	if !opts.Words {
+		c.Words = 0
+	}
+
+	if !opts.Chars {
+		c.Chars = 0
+	}
+
+	if !opts.Bytes {
+		c.Bytes = 0
+	}
+
+	if !opts.MaxLineLength {
+		c.MaxLineLength = 0
+	}
+
+	return c
+}
+
+// invalidBytes returns the counts of n bytes that are not part of any
+// character: they take no columns and neither start nor end a word
+func invalidBytes(opts Options, n int) Partial {
+	return Partial{
+		Options:  opts,
+		Counts:   Counts{Bytes: int64(n)},
+		OpenLine: n > 0,
+	}
+}
+
+// decodeBytes returns the counts of b decoded on its own, with no character
+// left incomplete at either edge
+func decodeBytes(opts Options, b []byte) Partial {
+	c := newCounter(opts)
+	c.headDone = true
+
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		c.rune(r, size)
+		b = b[size:]
+	}
+
+	return c.partial()
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,88 @@
+// Package wc counts the newlines, words, characters and bytes of a text and
+// the display width of its longest line, the way the wc command does.
+//
+// Counting is done in a single streaming pass over an io.Reader, so memory
+// use does not depend on the size of the input or the length of its lines.
+package wc
+
+import (
+	"context"
+	"io"
+)
+
+// Counts holds the metrics computed for an input
+type Counts struct {
+	Lines         int64
+	Words         int64
+	Chars         int64
+	Bytes         int64
+	MaxLineLength int64
+}
+
+// Add accumulates c2 into c, keeping the longest line of the two
+func (c *Counts) Add(c2 Counts) {
+	c.Lines += c2.Lines
+	c.Words += c2.Words
+	c.Chars += c2.Chars
+	c.Bytes += c2.Bytes
+
+	if c2.MaxLineLength > c.MaxLineLength {
+		c.MaxLineLength = c2.MaxLineLength
+	}
+}
+
+// Options selects the metrics to compute and how to compute them. Metrics
+// that are not selected are left at zero and cost nothing to skip; when none
+// is selected, all of them are computed.
+type Options struct {
+	Lines         bool
+	Words         bool
+	Chars         bool
+	Bytes         bool
+	MaxLineLength bool
+
+	// LogicalLines also counts a final line that has no terminating
+	// newline, which POSIX wc does not
+	LogicalLines bool
+
+	// Charset selects how bytes are split into characters and words
+	Charset Charset
+
+	// POSIXSpaces only separates words with the white space characters
+	// of POSIX, so that non-breaking spaces are part of words, as GNU wc
+	// does when POSIXLY_CORRECT is set
+	POSIXSpaces bool
+}
+
+// Any reports whether at least one metric is selected
+func (opts Options) Any() bool {
+	return opts.Lines || opts.Words || opts.Chars || opts.Bytes || opts.MaxLineLength
+}
+
+// All returns opts with every metric selected
+func (opts Options) All() Options {
+	opts.Lines = true
+	opts.Words = true
+	opts.Chars = true
+	opts.Bytes = true
+	opts.MaxLineLength = true
+
+	return opts
+}
+
+// Count computes the metrics of r selected by opts, reading it in
+// fixed-size buffers until EOF. It stops early with the context's error when
+// ctx is done.
+func Count(ctx context.Context, r io.Reader, opts Options) (Counts, error) {
+	p, err := CountPartial(ctx, r, opts)
+	if err != nil {
+		return Counts{}, err
+	}
+
+	return p.Total(), nil
+}
+
+// CountString computes the metrics of s selected by opts
+func CountString(s string, opts Options) Counts {
+	return CountPartialBytes([]byte(s), opts).Total()
+}
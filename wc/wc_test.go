@@ -0,0 +1,212 @@
+package wc_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/supreeth7/wcg/wc"
+)
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     wc.Options
+		expected wc.Counts
+	}{
+		{
+			name:     "counting every metric when none is selected",
+			input:    "hello\ngo\nlang",
+			expected: wc.Counts{Lines: 2, Words: 3, Chars: 13, Bytes: 13, MaxLineLength: 5},
+		},
+		{
+			name:     "counting only the selected metrics",
+			input:    "hello\ngo\nlang",
+			opts:     wc.Options{Lines: true, Bytes: true},
+			expected: wc.Counts{Lines: 2, Bytes: 13},
+		},
+		{
+			name:     "counting logical lines",
+			input:    "hello\ngo\nlang",
+			opts:     wc.Options{Lines: true, LogicalLines: true},
+			expected: wc.Counts{Lines: 3},
+		},
+		{
+			name:     "counting multibyte characters",
+			input:    "日本語 テキスト\n",
+			expected: wc.Counts{Lines: 1, Words: 2, Chars: 9, Bytes: 23, MaxLineLength: 15},
+		},
+		{
+			name:     "counting only characters",
+			input:    "日本語 \x80テキスト\nab\xe6\x97",
+			opts:     wc.Options{Chars: true},
+			expected: wc.Counts{Chars: 11},
+		},
+		{
+			name:     "separating words with non-breaking spaces",
+			input:    "a\u00a0b\u2007c\u202fd\u2060e f\n",
+			opts:     wc.Options{Words: true},
+			expected: wc.Counts{Words: 6},
+		},
+		{
+			name:     "keeping non-breaking spaces in words as POSIX does",
+			input:    "a\u00a0b\u2007c\u202fd\u2060e f\n",
+			opts:     wc.Options{Words: true, POSIXSpaces: true},
+			expected: wc.Counts{Words: 2},
+		},
+		{
+			name:     "counting bytes as characters in the C locale",
+			input:    "日本語 text\n",
+			opts:     wc.Options{Charset: wc.CharsetBytes}.All(),
+			expected: wc.Counts{Lines: 1, Words: 1, Chars: 15, Bytes: 15, MaxLineLength: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := wc.CountString(tt.input, tt.opts)
+			if actual != tt.expected {
+				t.Errorf("Actual:%+v Expected:%+v", actual, tt.expected)
+			}
+
+			// reading a byte at a time splits every multibyte character
+			// across reads
+			streamed, err := wc.Count(context.Background(), iotest.OneByteReader(strings.NewReader(tt.input)), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if streamed != tt.expected {
+				t.Errorf("Streamed:%+v Expected:%+v", streamed, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCountCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := wc.Count(ctx, strings.NewReader("hello"), wc.Options{}); err != context.Canceled {
+		t.Errorf("Actual:%v Expected:%v", err, context.Canceled)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	inputs := []string{
+		"hello\ngo\nlang",
+		"日本語\tテキスト \t\tx\r\nab\tcd\n",
+		"\x80\x80\x80\x80a\xe6\x97\xa5\xe6\x97 \xf0\x9f\x98\x80\xed\xa0\x80\xf0",
+		"\x01word\x02 \x03\xc2\xa0next\f\vend\t",
+		"e\xcc\x81\xef\xbc\xa1\rwide\twidth\n\n",
+	}
+
+	options := []wc.Options{
+		wc.Options{LogicalLines: true}.All(),
+		wc.Options{LogicalLines: true, Charset: wc.CharsetBytes}.All(),
+		{Lines: true, Bytes: true},
+		{Lines: true, Chars: true},
+	}
+
+	for _, opts := range options {
+		for _, input := range inputs {
+			expected := wc.CountString(input, opts)
+
+			for i := 0; i <= len(input); i++ {
+				for j := i; j <= len(input); j++ {
+					a := wc.CountPartialBytes([]byte(input[:i]), opts)
+					b := wc.CountPartialBytes([]byte(input[i:j]), opts)
+					c := wc.CountPartialBytes([]byte(input[j:]), opts)
+
+					if actual := a.Merge(b).Merge(c).Total(); actual != expected {
+						t.Fatalf("%q split at %d and %d: Actual:%+v Expected:%+v", input, i, j, actual, expected)
+					}
+
+					if actual := a.Merge(b.Merge(c)).Total(); actual != expected {
+						t.Fatalf("%q split at %d and %d: Actual:%+v Expected:%+v", input, i, j, actual, expected)
+					}
+				}
+			}
+
+			// merging every byte as its own chunk
+			var merged wc.Partial
+			for k := 0; k < len(input); k++ {
+				merged = merged.Merge(wc.CountPartialBytes([]byte(input[k:k+1]), opts))
+			}
+
+			if actual := merged.Total(); actual != expected {
+				t.Errorf("%q merged byte by byte: Actual:%+v Expected:%+v", input, actual, expected)
+			}
+		}
+	}
+}
+
+func TestCountAt(t *testing.T) {
+	input := strings.Repeat("日本語\tテキスト \x80word\r\n\xf0\x9f\x98\x80 ", 20000)
+
+	for _, opts := range []wc.Options{wc.Options{}.All(), {Charset: wc.CharsetBytes}} {
+		expected := wc.CountString(input, opts)
+
+		for _, jobs := range []int{1, 2, 3, 4, 5, 6, 7, 100000} {
+			actual, err := wc.CountAt(context.Background(), strings.NewReader(input), int64(len(input)), opts, jobs)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if actual != expected {
+				t.Errorf("%d jobs: Actual:%+v Expected:%+v", jobs, actual, expected)
+			}
+		}
+	}
+}
+
+func TestSplitRanges(t *testing.T) {
+	sizes := []int64{0, 1, 32 << 10, 16<<20 + 104, 5 << 30}
+
+	for _, size := range sizes {
+		for _, jobs := range []int{0, 1, 7, 1000, 100000, 1 << 30} {
+			ranges := wc.SplitRanges(size, jobs)
+
+			if len(ranges) == 0 || (jobs > 0 && len(ranges) > jobs) {
+				t.Errorf("size %d, %d jobs: Actual:%d ranges", size, jobs, len(ranges))
+				continue
+			}
+
+			var off int64
+			for _, rg := range ranges {
+				if rg.Off != off || (rg.Len <= 0 && size > 0) {
+					t.Errorf("size %d, %d jobs: Actual:%+v Expected:a range at %d", size, jobs, rg, off)
+				}
+
+				off += rg.Len
+			}
+
+			if off != size {
+				t.Errorf("size %d, %d jobs: Actual:%d bytes Expected:%d", size, jobs, off, size)
+			}
+		}
+	}
+}
+
+func TestLocaleCharset(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected wc.Charset
+	}{
+		{"", wc.CharsetBytes},
+		{"C", wc.CharsetBytes},
+		{"POSIX", wc.CharsetBytes},
+		{"C.UTF-8", wc.CharsetUTF8},
+		{"en_US.utf8", wc.CharsetUTF8},
+		{"de_DE.UTF-8@euro", wc.CharsetUTF8},
+		{"en_US.ISO-8859-1", wc.CharsetBytes},
+	}
+
+	for _, test := range tests {
+		if actual := wc.LocaleCharset(test.name); actual != test.expected {
+			t.Errorf("LocaleCharset(%q): Actual:%d Expected:%d", test.name, actual, test.expected)
+		}
+	}
+}
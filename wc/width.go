@@ -0,0 +1,35 @@
+package wc
+
+import "unicode"
+
+//go:generate go run gen_width_table.go
+
+// tabWidth is the distance between tab stops
+const tabWidth = 8
+
+// runeWidth returns the number of columns r takes on a terminal, like
+// wcwidth(3): East Asian wide and fullwidth characters take two columns,
+// while combining marks, format and control characters take none.
+func runeWidth(r rune) int {
+	switch {
+	case r < 0x20, r >= 0x7f && r < 0xa0:
+		return 0
+	case r < 0x7f:
+		return 1
+	case r == 0xad:
+		// the soft hyphen is a format character, but is displayed
+		return 1
+	case r >= 0x1160 && r <= 0x11ff, r == 0x200b:
+		// Hangul medial vowels and final consonants combine with the
+		// preceding initial consonant
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case unicode.Is(wideTable, r):
+		return 2
+	case !unicode.IsGraphic(r) && !unicode.Is(unicode.Co, r):
+		return 0
+	}
+
+	return 1
+}
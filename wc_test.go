@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/supreeth7/wcg/cmd"
@@ -52,6 +53,12 @@ func TestWc(t *testing.T) {
 
 	t.Run("counting lines in a file", func(t *testing.T) {
 		actual := cmd.GetLineCount(data)
+		expected := 2
+		assertCorrectMessage(t, actual, expected)
+	})
+
+	t.Run("counting logical lines in a file", func(t *testing.T) {
+		actual := cmd.GetLogicalLineCount(data)
 		expected := 3
 		assertCorrectMessage(t, actual, expected)
 	})
@@ -62,6 +69,12 @@ func TestWc(t *testing.T) {
 		assertCorrectMessage(t, actual, expected)
 	})
 
+	t.Run("skipping invalid UTF-8 when counting characters", func(t *testing.T) {
+		actual := cmd.GetCharacterCount("a\x80b\xed\xa0\x80")
+		expected := 2
+		assertCorrectMessage(t, actual, expected)
+	})
+
 	t.Run("counting bytes in a file", func(t *testing.T) {
 		actual := cmd.GetByteCount(data)
 		expected := 13
@@ -73,4 +86,33 @@ func TestWc(t *testing.T) {
 		expected := 5
 		assertCorrectMessage(t, actual, expected)
 	})
+
+	t.Run("measuring the display width of wide characters and tabs", func(t *testing.T) {
+		actual := cmd.GetMaxLineLength("日本語\tx\n")
+		expected := 9
+		assertCorrectMessage(t, actual, expected)
+	})
+
+	t.Run("counting a line longer than the scanner token limit", func(t *testing.T) {
+		actual := cmd.GetMaxLineLength(strings.Repeat("é", 100000))
+		expected := 100000
+		assertCorrectMessage(t, actual, expected)
+	})
+}
+
+func BenchmarkGetCounts(b *testing.B) {
+	data, err := cmd.ConvertFileToString("sample-2mb-text-file.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(int64(len(data)))
+
+	for i := 0; i < b.N; i++ {
+		cmd.GetLineCount(data)
+		cmd.GetWordCount(data)
+		cmd.GetByteCount(data)
+		cmd.GetCharacterCount(data)
+		cmd.GetMaxLineLength(data)
+	}
 }